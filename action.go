@@ -0,0 +1,103 @@
+package goargs
+
+import (
+	"fmt"
+)
+
+// 参数出现在命令行时执行的动作，按照参数在命令行中的顺序执行
+// 多个Option可以通过Dest共享同一个dest，动作通过Context读写dest的值
+type Action interface {
+	// 是否需要参数值，比如 --mode test
+	NeedValue() bool
+	// 执行动作，value为参数值，不需要参数值时为空
+	Run(c *Context, dest string, value string) error
+}
+
+type storeConstAction struct {
+	v interface{}
+}
+
+func (self *storeConstAction) NeedValue() bool {
+	return false
+}
+
+func (self *storeConstAction) Run(c *Context, dest string, value string) error {
+	return c.SetValue(dest, self.v)
+}
+
+type appendConstAction struct {
+	v interface{}
+}
+
+func (self *appendConstAction) NeedValue() bool {
+	return false
+}
+
+func (self *appendConstAction) Run(c *Context, dest string, value string) error {
+	var list []interface{}
+	if c.IsSet(dest) {
+		list = toList(c.Value(dest))
+	}
+	return c.SetValue(dest, append(list, self.v))
+}
+
+type callbackAction struct {
+	fn func(c *Context, value string) error
+}
+
+func (self *callbackAction) NeedValue() bool {
+	return true
+}
+
+// 先保存参数值，再执行回调
+func (self *callbackAction) Run(c *Context, dest string, value string) error {
+	if err := c.SetValue(dest, value); err != nil {
+		return err
+	}
+	return self.fn(c, value)
+}
+
+// 参数出现时，dest的值为v
+func (self *Option) StoreConst(v interface{}) *Option {
+	return self.Action(&storeConstAction{v: v})
+}
+
+// 参数出现时，将v追加到dest的列表中
+func (self *Option) AppendConst(v interface{}) *Option {
+	return self.Action(&appendConstAction{v: v})
+}
+
+// 参数出现时执行回调，value为参数值；设置了Bool时不需要参数值
+func (self *Option) Callback(fn func(c *Context, value string) error) *Option {
+	return self.Action(&callbackAction{fn: fn})
+}
+
+func (self *Option) Action(a Action) *Option {
+	self.action = a
+	return self
+}
+
+// 将参数值转换为列表
+func toList(v interface{}) []interface{} {
+	switch l := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return l
+	case []string:
+		out := make([]interface{}, 0, len(l))
+		for _, s := range l {
+			out = append(out, s)
+		}
+		return out
+	}
+	return []interface{}{v}
+}
+
+func toStringList(l []interface{}) []string {
+	out := make([]string, 0, len(l))
+	for _, v := range l {
+		out = append(out, fmt.Sprint(v))
+	}
+	return out
+}
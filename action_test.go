@@ -0,0 +1,159 @@
+package goargs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 统计参数出现的次数，比如 -vvv
+type countAction struct{}
+
+func (self *countAction) NeedValue() bool {
+	return false
+}
+
+func (self *countAction) Run(c *Context, dest string, value string) error {
+	n, _ := c.Value(dest).(int)
+	return c.SetValue(dest, n+1)
+}
+
+func TestActionStoreConst(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("level", "log level").Default("info")
+	parser.AddOption("debug", "debug log").Long("debug").Dest("level").StoreConst("debug")
+	parser.AddOption("quiet", "quiet log").Short('q').Dest("level").StoreConst("error")
+
+	result := parser.ParseArgs([]string{})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	level, _ := result.cx.GetString("level")
+	assertEqual(t, "info", level)
+
+	parser = ArgumentParser("app", "help")
+	parser.AddOption("level", "log level").Default("info")
+	parser.AddOption("debug", "debug log").Long("debug").Dest("level").StoreConst("debug")
+	parser.AddOption("quiet", "quiet log").Short('q').Dest("level").StoreConst("error")
+
+	// 按照命令行的顺序执行，后出现的生效
+	result = parser.ParseArgs([]string{"-q", "--debug"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	level, _ = result.cx.GetString("level")
+	assertEqual(t, "debug", level)
+	assertEqual(t, "command line", result.cx.Source("level").String())
+}
+
+func TestActionAppendConst(t *testing.T) {
+	var langs []string
+
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("langs", "languages").Long("lang").StringSliceVar(&langs)
+	parser.AddOption("py", "add python").Long("py").Dest("langs").AppendConst("python")
+	parser.AddOption("go", "add go").Short('g').Dest("langs").AppendConst("go")
+
+	result := parser.ParseArgs([]string{"--py", "--lang", "c,java", "-g"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "python,c,java,go", strings.Join(langs, ","))
+
+	// 共享dest时，值的类型由保存值的Option决定
+	v, ok := result.cx.Value("langs").([]string)
+	if !ok || len(v) != 4 {
+		t.Error(result.cx.Value("langs"))
+	}
+}
+
+func TestActionSharedDest(t *testing.T) {
+	// 未设置Short和Long时，默认的long为AddOption时的名称
+	var tags []string
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("tags", "tags").StringSliceVar(&tags)
+	parser.AddOption("a", "add a").AppendConst("A").Dest("tags")
+	parser.AddOption("level", "log level")
+	parser.AddOption("fast", "fast mode").Dest("level").StoreConst("fast")
+
+	result := parser.ParseArgs([]string{"--a", "--tags", "b", "--fast"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "A,b", strings.Join(tags, ","))
+	level, _ := result.cx.GetString("level")
+	assertEqual(t, "fast", level)
+	assertEqual(t, "--fast", parser.Opts["fast"].flags())
+}
+
+func TestActionSharedDestHolder(t *testing.T) {
+	// 没有与dest同名的Option时，由先定义的Option保存值
+	for i := 0; i < 20; i++ {
+		parser := ArgumentParser("app", "help")
+		parser.AddOption("fast", "fast mode").Long("fast").Dest("speed").StoreConst("fast").Default("normal")
+		parser.AddOption("slow", "slow mode").Long("slow").Dest("speed").StoreConst("slow")
+
+		result := parser.ParseArgs([]string{})
+		if result.err != nil {
+			t.Fatal(result.err)
+		}
+		speed, _ := result.cx.GetString("speed")
+		assertEqual(t, "normal", speed)
+	}
+}
+
+func TestActionCallback(t *testing.T) {
+	seen := []string{}
+
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode").Short('m').Callback(func(c *Context, value string) error {
+		seen = append(seen, "mode="+value)
+		return nil
+	})
+	parser.AddOption("force", "force").Short('f').Bool(true).Callback(func(c *Context, value string) error {
+		mode, _ := c.GetString("mode")
+		seen = append(seen, "force="+value+",mode="+mode)
+		return nil
+	})
+	parser.AddOption("check", "check").Long("check").Callback(func(c *Context, value string) error {
+		return errors.New("bad check " + value)
+	})
+
+	result := parser.ParseArgs([]string{"-m", "debug", "-f"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "mode=debug|force=true,mode=debug", strings.Join(seen, "|"))
+	mode, _ := result.cx.GetString("mode")
+	assertEqual(t, "debug", mode)
+	force, _ := result.cx.GetBool("force")
+	if !force {
+		t.Error("Expect force is true")
+	}
+
+	result = parser.ParseArgs([]string{"--check", "x"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "bad check x", result.err.Error())
+}
+
+func TestActionCustom(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("verbose", "verbose").Short('v').Default(0).Action(&countAction{})
+	count := Add[int](parser, "count", "count").Short('c').Default(0)
+	count.Option().Action(&countAction{})
+
+	result := parser.ParseArgs([]string{"-v", "-c", "-v", "-v"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	if v, _ := result.cx.GetInt("verbose"); v != 3 {
+		t.Error(v)
+	}
+	assertEqualInt(t, 1, count.Get(result.cx))
+
+	if err := result.cx.SetValue("missing", 1); err == nil {
+		t.Error("Expect error for the unknown dest")
+	}
+}
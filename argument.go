@@ -0,0 +1,83 @@
+package goargs
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// 添加位置参数，按声明顺序对应命令行中不以-开头的参数，比如 app upload a.txt b.txt
+// 位置参数默认必选，设置Default后可以省略；StringSliceVar的位置参数接收剩余的所有参数，只能是最后一个
+// 位置参数只属于当前命令，不被子命令继承
+func (self *Parser) AddArgument(dest string, help string) *Option {
+	arg := newOption(dest, help, self)
+	arg.isArg = true
+	arg.requiredV = true
+	arg.local = true
+	self.args = append(self.args, arg)
+	self.changed()
+	return arg
+}
+
+// 将位置参数的值按顺序赋给位置参数，多余的参数报错
+func (self *Parser) bindArgs(cx *Context, values []string) (err error) {
+	for _, a := range self.args {
+		if len(values) == 0 {
+			break
+		}
+		// 与Option一样通过apply处理，执行弃用警告以及Action
+		if a.multi {
+			for _, v := range values {
+				if err = a.apply(cx, v); err != nil {
+					return
+				}
+			}
+			values = nil
+			break
+		}
+		if err = a.apply(cx, values[0]); err != nil {
+			return
+		}
+		values = values[1:]
+	}
+	if len(values) > 0 {
+		err = fmt.Errorf("Unrecognized arguments: %s", strings.Join(values, " "))
+	}
+	return
+}
+
+// 用法中的位置参数，比如 <src>...，可以省略的为 [<src>]
+func (self *Option) argString() string {
+	name := self.metavarV
+	if name == "" {
+		name = self.dest
+	}
+	s := "<" + name + ">"
+	if self.multi {
+		s += "..."
+	}
+	if !self.requiredV || self.defValue != nil {
+		s = "[" + s + "]"
+	}
+	return s
+}
+
+func (self *Parser) ArgumentText() string {
+	return self.argumentText(helpStyle{})
+}
+
+// 位置参数的帮助信息
+func (self *Parser) argumentText(s helpStyle) string {
+	buf := new(bytes.Buffer)
+	names := []string{}
+	helps := []string{}
+	for _, v := range self.args {
+		// 与Option的缩进一致
+		names = append(names, "  "+strings.Trim(v.argString(), "[]"))
+		helps = append(helps, v.detailHelp(s))
+	}
+	for _, line := range alignRows(names, helps, s.lineWidth()) {
+		fmt.Fprintln(buf, line)
+	}
+	return buf.String()
+}
@@ -0,0 +1,162 @@
+package goargs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestArguments(t *testing.T) {
+	srcs := []string{}
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode")
+	upload := parser.AddParser("upload", "upload files")
+	upload.AddOption("file", "remote file").Long("file").Metavar("FILE").Required()
+	upload.AddOption("force", "force upload").Short('f').Bool(true)
+	upload.AddArgument("src", "local files").StringSliceVar(&srcs)
+	upload.AddParser("part", "upload a part")
+
+	cp := parser.AddParser("cp", "copy file")
+	cp.AddArgument("from", "source")
+	cp.AddArgument("to", "target").Default(".")
+
+	result := parser.ParseArgs([]string{"upload", "a.txt", "--file", "/x", "b.txt", "-f", "--", "-c.txt"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "upload", result.Title)
+	assertEqual(t, "a.txt,b.txt,-c.txt", strings.Join(srcs, ","))
+	force, _ := result.cx.GetBool("force")
+	if !force {
+		t.Error("Expect force is true")
+	}
+
+	// 与子命令同名时为子命令
+	result = parser.ParseArgs([]string{"upload", "part", "--file", "/x"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "part", result.Title)
+
+	result = parser.ParseArgs([]string{"cp", "a.txt", "-m", "fast"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	from, _ := result.cx.GetString("from")
+	assertEqual(t, "a.txt", from)
+	to, _ := result.cx.GetString("to")
+	assertEqual(t, ".", to)
+
+	result = parser.ParseArgs([]string{"cp", "a.txt", "b.txt", "c.txt"})
+	assertEqual(t, "Unrecognized arguments: c.txt", result.err.Error())
+
+	result = parser.ParseArgs([]string{"cp"})
+	assertEqual(t, "Missing required argument: '<from>'", result.err.Error())
+
+	// 没有位置参数的命令
+	result = parser.ParseArgs([]string{"-m", "fast", "x"})
+	assertEqual(t, "Unrecognized arguments: x", result.err.Error())
+	if result = parser.ParseArgs([]string{"download"}); result.err != ERR_NotFound {
+		t.Error(result.err)
+	}
+}
+
+func TestUsageLine(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode")
+	upload := parser.AddParser("upload", "upload files")
+	upload.AddOption("file", "remote file").Long("file").Metavar("FILE").Required()
+	upload.AddOption("force", "force upload").Short('f').Bool(true)
+	upload.AddArgument("src", "local files").StringSliceVar(new([]string))
+	upload.AddParser("part", "upload a part")
+
+	cp := parser.AddParser("cp", "copy file")
+	cp.AddArgument("from", "source")
+	cp.AddArgument("to", "target").Default(".")
+	parser.SetUsageTemplate("{{ .CommandPath }} {{ .OptionText }}")
+
+	usage, _ := parser.Usage()
+	assertEqual(t, "app [-m/--mode MODE] <command>", usage)
+
+	usage, _ = parser.Subs["upload"].Usage()
+	assertEqual(t, "app upload [global options] --file FILE [-f] <src>... <command>", usage)
+
+	usage, _ = parser.Subs["cp"].Usage()
+	assertEqual(t, "app cp [global options] <from> [<to>]", usage)
+
+	usage, _ = parser.Subs["upload"].Subs["part"].Usage()
+	assertEqual(t, "app upload part [global options]", usage)
+
+	assertEqual(t, "  <from> source\n  <to>   target\n", parser.Subs["cp"].ArgumentText())
+}
+
+func TestArgumentsAfterDoubleDash(t *testing.T) {
+	srcs := []string{}
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode")
+	upload := parser.AddParser("upload", "upload files")
+	upload.AddOption("file", "remote file").Long("file").Metavar("FILE").Required()
+	upload.AddOption("force", "force upload").Short('f').Bool(true)
+	upload.AddArgument("src", "local files").StringSliceVar(&srcs)
+	upload.AddParser("part", "upload a part")
+
+	cp := parser.AddParser("cp", "copy file")
+	cp.AddArgument("from", "source")
+	cp.AddArgument("to", "target").Default(".")
+
+	// -- 之后类似Option的参数也作为位置参数，位置参数的值不按逗号拆分
+	result := parser.ParseArgs([]string{"upload", "--file", "/x", "--", "-f", "--file=y", "-h", "a,b.txt"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "-f|--file=y|-h|a,b.txt", strings.Join(srcs, "|"))
+	force, _ := result.cx.GetBool("force")
+	if force {
+		t.Error("Expect force is false")
+	}
+	file, _ := result.cx.GetString("file")
+	assertEqual(t, "/x", file)
+
+	result = parser.ParseArgs([]string{"cp", "--", "--from", "-m"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	from, _ := result.cx.GetString("from")
+	assertEqual(t, "--from", from)
+	to, _ := result.cx.GetString("to")
+	assertEqual(t, "-m", to)
+	if result.cx.IsSet("mode") {
+		t.Error("Expect mode is not set")
+	}
+}
+
+func TestArgumentsApply(t *testing.T) {
+	buf := new(bytes.Buffer)
+	seen := []string{}
+	parser := ArgumentParser("app", "help")
+	parser.SetErrOutput(buf)
+	parser.AddArgument("src", "local files").StringSliceVar(new([]string)).Deprecated("use --src instead").
+		Choices("a.txt", "b.txt").
+		Callback(func(c *Context, value string) error {
+			seen = append(seen, value)
+			return nil
+		})
+
+	result := parser.ParseArgs([]string{"a.txt", "b.txt"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	// 每个值都执行Action以及弃用警告
+	assertEqual(t, "a.txt|b.txt", strings.Join(seen, "|"))
+	assertEqual(t, ""+
+		"warning: Option '<src>...' is deprecated: use --src instead\n"+
+		"warning: Option '<src>...' is deprecated: use --src instead\n", buf.String())
+	srcs, _ := result.cx.GetStringSlice("src")
+	assertEqual(t, "a.txt|b.txt", strings.Join(srcs, "|"))
+
+	if result = parser.ParseArgs([]string{"a.txt", "c.txt"}); result.err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Invalid choice 'c.txt' for option: '<src>...' (choose from a.txt, b.txt)", result.err.Error())
+	}
+}
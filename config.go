@@ -0,0 +1,474 @@
+package goargs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 配置文件中的一个值，记录行号用于报错
+type configValue struct {
+	value string
+	line  int
+}
+
+// 配置文件中的一节，嵌套的section对应子命令
+type configSection struct {
+	values map[string]*configValue
+	subs   map[string]*configSection
+}
+
+type configFile struct {
+	path string
+	root *configSection
+}
+
+func newConfigSection() *configSection {
+	return &configSection{
+		values: map[string]*configValue{},
+		subs:   map[string]*configSection{},
+	}
+}
+
+// 根据扩展名选择解析方式：.json、.yaml/.yml，其余按INI处理
+func loadConfigFile(path string) (*configFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *configSection
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		root, err = parseJSONConfig(data)
+	case ".yaml", ".yml":
+		root, err = parseYAMLConfig(data)
+	default:
+		root, err = parseINIConfig(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return &configFile{path: path, root: root}, nil
+}
+
+// 获取parser对应的section，parser在配置文件中没有对应的section时返回nil
+func (self *configFile) section(parser *Parser) *configSection {
+	if parser.Super == nil {
+		return self.root
+	}
+	sec := self.section(parser.Super)
+	if sec == nil {
+		return nil
+	}
+	return sec.subs[parser.Name]
+}
+
+func parseJSONConfig(data []byte) (*configSection, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	lineOf := func(offset int64) int {
+		return bytes.Count(data[:offset], []byte{'\n'}) + 1
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, errors.New("expect a JSON object")
+	}
+	return readJSONObject(dec, lineOf)
+}
+
+func readJSONObject(dec *json.Decoder, lineOf func(int64) int) (*configSection, error) {
+	sec := newConfigSection()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+		line := lineOf(dec.InputOffset())
+
+		if tok, err = dec.Token(); err != nil {
+			return nil, err
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			if v == '{' {
+				sub, err := readJSONObject(dec, lineOf)
+				if err != nil {
+					return nil, err
+				}
+				sec.subs[key] = sub
+				continue
+			}
+			// 数组只支持标量，按逗号拼接
+			items := []string{}
+			for dec.More() {
+				if tok, err = dec.Token(); err != nil {
+					return nil, err
+				}
+				if _, ok := tok.(json.Delim); ok {
+					return nil, fmt.Errorf("line %d: nested value in array '%s'", line, key)
+				}
+				items = append(items, jsonScalar(tok))
+			}
+			if _, err = dec.Token(); err != nil {
+				return nil, err
+			}
+			sec.values[key] = &configValue{value: strings.Join(items, ","), line: line}
+		case nil:
+			// null 等同于未设置
+		default:
+			sec.values[key] = &configValue{value: jsonScalar(v), line: line}
+		}
+	}
+	// 读取结尾的 '}'
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return sec, nil
+}
+
+func jsonScalar(tok interface{}) string {
+	switch v := tok.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// 类TOML的INI格式：
+//
+//	# 注释
+//	mode = "test"
+//	[upload]
+//	file = readme.md
+//	[upload.part]
+//	size = 1024
+func parseINIConfig(data []byte) (*configSection, error) {
+	root := newConfigSection()
+	sec := root
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || text[0] == '#' || text[0] == ';' {
+			continue
+		}
+
+		if text[0] == '[' {
+			if text[len(text)-1] != ']' {
+				return nil, fmt.Errorf("line %d: bad section '%s'", line, text)
+			}
+			sec = root
+			for _, name := range strings.Split(text[1:len(text)-1], ".") {
+				name = strings.TrimSpace(name)
+				if len(name) == 0 {
+					return nil, fmt.Errorf("line %d: bad section '%s'", line, text)
+				}
+				sub, ok := sec.subs[name]
+				if !ok {
+					sub = newConfigSection()
+					sec.subs[name] = sub
+				}
+				sec = sub
+			}
+			continue
+		}
+
+		split := strings.SplitN(text, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("line %d: expect 'key = value' but '%s'", line, text)
+		}
+		key := strings.TrimSpace(split[0])
+		value, err := configScalar(strings.TrimSpace(split[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		sec.values[key] = &configValue{value: value, line: line}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// YAML的子集，只支持嵌套的map，标量以及标量列表：
+//
+//	mode: test
+//	upload:
+//	  file: readme.md
+//	  tags:
+//	    - a
+//	    - b
+func parseYAMLConfig(data []byte) (*configSection, error) {
+	type frame struct {
+		indent int
+		sec    *configSection
+	}
+	// 值为空的key，根据下一行决定是子section还是列表
+	type pendingKey struct {
+		key    string
+		indent int
+		line   int
+		sec    *configSection
+		items  []string
+		isList bool
+	}
+
+	stack := []frame{{indent: -1, sec: newConfigSection()}}
+	var pending *pendingKey
+
+	flush := func() {
+		if pending != nil {
+			pending.sec.values[pending.key] = &configValue{value: strings.Join(pending.items, ","), line: pending.line}
+			pending = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := scanner.Text()
+		text := strings.TrimSpace(raw)
+		if len(text) == 0 || text[0] == '#' || text == "---" {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		isItem := text == "-" || strings.HasPrefix(text, "- ")
+
+		if pending != nil && indent > pending.indent {
+			if isItem {
+				item, err := configScalar(strings.TrimSpace(text[1:]))
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %s", line, err)
+				}
+				pending.items = append(pending.items, item)
+				pending.isList = true
+				continue
+			}
+			if !pending.isList {
+				sub := newConfigSection()
+				pending.sec.subs[pending.key] = sub
+				stack = append(stack, frame{indent: pending.indent, sec: sub})
+				pending = nil
+			}
+		}
+		flush()
+
+		if isItem {
+			return nil, fmt.Errorf("line %d: unexpected list item", line)
+		}
+
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		sec := stack[len(stack)-1].sec
+
+		split := strings.SplitN(text, ":", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("line %d: expect 'key: value' but '%s'", line, text)
+		}
+		key := strings.TrimSpace(split[0])
+		rawValue := strings.TrimSpace(split[1])
+		// 只有注释的值，比如 upload: # 上传
+		if strings.HasPrefix(rawValue, "#") {
+			rawValue = ""
+		}
+		if len(rawValue) == 0 {
+			pending = &pendingKey{key: key, indent: indent, line: line, sec: sec}
+			continue
+		}
+		value, err := configScalar(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		sec.values[key] = &configValue{value: value, line: line}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return stack[0].sec, nil
+}
+
+// 解析标量：支持引号字符串以及 [a, b] 形式的列表（按逗号拼接），值之后可以有 # 开头的注释
+func configScalar(s string) (string, error) {
+	if len(s) == 0 {
+		return s, nil
+	}
+	// 引号或括号之后只允许注释
+	trailing := func(rest string) error {
+		rest = strings.TrimSpace(rest)
+		if len(rest) > 0 && rest[0] != '#' {
+			return fmt.Errorf("unexpected '%s' after %s", rest, s)
+		}
+		return nil
+	}
+	switch s[0] {
+	case '"':
+		q, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", fmt.Errorf("bad string %s", s)
+		}
+		if err = trailing(s[len(q):]); err != nil {
+			return "", err
+		}
+		v, err := strconv.Unquote(q)
+		if err != nil {
+			return "", fmt.Errorf("bad string %s", s)
+		}
+		return v, nil
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("bad string %s", s)
+		}
+		if err := trailing(s[end+2:]); err != nil {
+			return "", err
+		}
+		return s[1 : end+1], nil
+	case '[':
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return "", fmt.Errorf("bad list %s", s)
+		}
+		if err := trailing(s[end+1:]); err != nil {
+			return "", err
+		}
+		items := []string{}
+		for _, item := range strings.Split(s[1:end], ",") {
+			item = strings.TrimSpace(item)
+			if len(item) == 0 {
+				continue
+			}
+			v, err := configScalar(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, v)
+		}
+		return strings.Join(items, ","), nil
+	}
+	// 未加引号的值允许行尾注释
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s, nil
+}
+
+// 检查配置文件中的key是否都能对应到Option或子命令
+func (self *Parser) unknownConfigKeys(sec *configSection, path string) []string {
+	unknown := []string{}
+	for k := range sec.values {
+		if !self.hasDest(k) {
+			unknown = append(unknown, path+k)
+		}
+	}
+	for k, v := range sec.subs {
+		if sub, ok := self.Subs[k]; ok {
+			sub.build()
+			unknown = append(unknown, sub.unknownConfigKeys(v, path+k+".")...)
+		} else {
+			unknown = append(unknown, path+k)
+		}
+	}
+	return unknown
+}
+
+func (self *Parser) hasDest(dest string) bool {
+	for _, v := range self.Opts {
+		if v.dest == dest {
+			return true
+		}
+	}
+	return false
+}
+
+// 查找配置文件路径：优先使用标记为ConfigPath的Option，其次是SetConfigFile设置的路径
+// 路径来自默认值且文件不存在时，忽略配置文件
+func (self *Parser) findConfigFile(cx *Context) (path string, explicit bool) {
+	for _, o := range cx.options {
+		if o.isConfig {
+			v := cx.value(o)
+			if path = v.getString(); path != "" {
+				return path, v.stored
+			}
+		}
+	}
+	return self.Root.configFile, false
+}
+
+// 将环境变量以及配置文件中的值填充到命令行未设置的Option
+// 优先级：命令行 > 环境变量 > 配置文件 > 默认值
+func (self *Parser) applySources(cx *Context) (err error) {
+	for _, o := range cx.options {
+		v := cx.value(o)
+		if v.stored || o.envV == "" {
+			continue
+		}
+		if s, ok := os.LookupEnv(o.envV); ok {
+			if err = v.set(s, Source{Kind: Env, Name: o.envV}); err != nil {
+				return
+			}
+		}
+	}
+
+	path, explicit := self.findConfigFile(cx)
+	if path == "" {
+		return
+	}
+	if _, err = os.Stat(path); os.IsNotExist(err) && !explicit {
+		return nil
+	}
+
+	config, err := loadConfigFile(path)
+	if err != nil {
+		return
+	}
+
+	unknown := self.Root.unknownConfigKeys(config.root, "")
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		if self.Root.configStrict {
+			return fmt.Errorf("Unknown config key '%s' in %s", k, path)
+		}
+		self.warnf("Unknown config key '%s' in %s", k, path)
+	}
+
+	for _, o := range cx.options {
+		v := cx.value(o)
+		if v.stored {
+			continue
+		}
+		sec := config.section(o.father)
+		if sec == nil {
+			continue
+		}
+		if cv, ok := sec.values[o.dest]; ok {
+			if err = v.set(cv.value, Source{Kind: ConfigFile, Path: path, Line: cv.line}); err != nil {
+				return fmt.Errorf("%s:%d: %s", path, cv.line, err)
+			}
+		}
+	}
+	return
+}
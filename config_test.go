@@ -0,0 +1,211 @@
+package goargs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseJSONConfig(t *testing.T) {
+	sec, err := parseJSONConfig([]byte(`{
+  "mode": "debug",
+  "retry": 3,
+  "force": true,
+  "tags": ["a", "b"],
+  "upload": {
+    "file": "readme.md"
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "debug", sec.values["mode"].value)
+	assertEqualInt(t, 2, sec.values["mode"].line)
+	assertEqual(t, "3", sec.values["retry"].value)
+	assertEqual(t, "true", sec.values["force"].value)
+	assertEqual(t, "a,b", sec.values["tags"].value)
+	assertEqual(t, "readme.md", sec.subs["upload"].values["file"].value)
+	assertEqualInt(t, 7, sec.subs["upload"].values["file"].line)
+}
+
+func TestParseINIConfig(t *testing.T) {
+	sec, err := parseINIConfig([]byte(`# comment
+mode = "debug"
+tags = [a, "b"]
+
+[upload]
+file = readme.md # inline comment
+; comment
+[upload.part]
+size = 1024
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "debug", sec.values["mode"].value)
+	assertEqual(t, "a,b", sec.values["tags"].value)
+	assertEqual(t, "readme.md", sec.subs["upload"].values["file"].value)
+	assertEqualInt(t, 6, sec.subs["upload"].values["file"].line)
+	assertEqual(t, "1024", sec.subs["upload"].subs["part"].values["size"].value)
+
+	if _, err = parseINIConfig([]byte("mode debug")); err == nil {
+		t.Fatal()
+	}
+}
+
+func TestParseYAMLConfig(t *testing.T) {
+	sec, err := parseYAMLConfig([]byte(`# comment
+mode: debug
+tags:
+  - a
+  - "b"
+upload:
+  file: readme.md
+  part:
+    size: 1024
+force: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "debug", sec.values["mode"].value)
+	assertEqual(t, "a,b", sec.values["tags"].value)
+	assertEqual(t, "readme.md", sec.subs["upload"].values["file"].value)
+	assertEqualInt(t, 7, sec.subs["upload"].values["file"].line)
+	assertEqual(t, "1024", sec.subs["upload"].subs["part"].values["size"].value)
+	assertEqual(t, "true", sec.values["force"].value)
+}
+
+func TestParseYAMLConfigComment(t *testing.T) {
+	sec, err := parseYAMLConfig([]byte(`name: "a #b"   # comment
+title: 'c #d' # comment
+mode: debug # comment
+upload: # comment
+  tags: ["x #1", y] # comment
+  files:
+    - "e #f" # comment
+    - g # comment
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "a #b", sec.values["name"].value)
+	assertEqual(t, "c #d", sec.values["title"].value)
+	assertEqual(t, "debug", sec.values["mode"].value)
+	assertEqual(t, "x #1,y", sec.subs["upload"].values["tags"].value)
+	assertEqual(t, "e #f,g", sec.subs["upload"].values["files"].value)
+
+	if _, err = parseYAMLConfig([]byte(`name: "a" b`)); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "line 1: unexpected 'b' after \"a\" b", err.Error())
+	}
+}
+
+func TestConfigFileApply(t *testing.T) {
+	path := writeConfig(t, "app.json", `{"mode": "debug", "force": true, "upload": {"file": "readme.md"}}`)
+
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Short('m').Default("test")
+	parser.AddOption("config", "config file").Short('c').ConfigPath()
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("file", "file name").Required()
+	result := parser.ParseArgs([]string{"upload", "-c", path})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+
+	mode, _ := result.cx.GetString("mode")
+	assertEqual(t, "debug", mode)
+	file, _ := result.cx.GetString("file")
+	assertEqual(t, "readme.md", file)
+	if force, _ := result.cx.GetBool("force"); !force {
+		t.Error("Expect force is true")
+	}
+}
+
+func TestConfigFilePrecedence(t *testing.T) {
+	path := writeConfig(t, "app.ini", "mode = config\nforce = false\n[upload]\nfile = config.md\n")
+
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Short('m').Default("test").Env("GOARGS_TEST_MODE")
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("file", "file name").Required()
+	parser.SetConfigFile(path)
+	os.Setenv("GOARGS_TEST_MODE", "env")
+	defer os.Unsetenv("GOARGS_TEST_MODE")
+
+	result := parser.ParseArgs([]string{"upload", "--file", "argv.md"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	mode, _ := result.cx.GetString("mode")
+	assertEqual(t, "env", mode)
+	file, _ := result.cx.GetString("file")
+	assertEqual(t, "argv.md", file)
+	if force, _ := result.cx.GetBool("force"); force {
+		t.Error("Expect force is false")
+	}
+}
+
+func TestConfigFileMissing(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("config", "config file").Short('c').ConfigPath()
+
+	// 默认路径不存在时忽略
+	parser.SetConfigFile(filepath.Join(t.TempDir(), "missing.json"))
+	result := parser.ParseArgs([]string{})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+
+	// 命令行指定的路径不存在时报错
+	result = parser.ParseArgs([]string{"-c", filepath.Join(t.TempDir(), "missing.json")})
+	if result.err == nil {
+		t.Fatal()
+	}
+}
+
+func TestConfigFileUnknownKey(t *testing.T) {
+	path := writeConfig(t, "app.ini", "mode = debug\n[upload]\nname = x\n")
+
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Short('m')
+	parser.AddParser("upload", "upload help").AddOption("file", "file name")
+	parser.SetConfigFile(path)
+	result := parser.ParseArgs([]string{"upload", "--file", "a"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+
+	parser.SetConfigStrict(true)
+	result = parser.ParseArgs([]string{"upload", "--file", "a"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Unknown config key 'upload.name' in "+path, result.err.Error())
+}
+
+func TestConfigFileBadValue(t *testing.T) {
+	path := writeConfig(t, "app.ini", "force = maybe\n")
+
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	parser.SetConfigFile(path)
+	result := parser.ParseArgs([]string{})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, path+":1: Invalid bool value 'maybe' for option: '-f'", result.err.Error())
+}
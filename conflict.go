@@ -0,0 +1,147 @@
+package goargs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 定义Option时，Short、Long或名称重复的处理方式
+type ConflictHandler int
+
+const (
+	// 默认，记录错误，ParseArgs时返回；子命令的Option覆盖父命令的Option也视为冲突
+	ConflictError ConflictHandler = iota
+	// 后定义的Option生效，先定义的Option失去该名称；允许子命令覆盖父命令的Option
+	ConflictResolve
+	// 同一命令中重复时记录错误，允许子命令覆盖父命令的Option
+	ConflictShadow
+)
+
+// 设置当前Parser的冲突处理方式，之后添加的子命令默认继承该设置
+func (self *Parser) SetConflictHandler(h ConflictHandler) {
+	self.conflict = h
+}
+
+// 定义时发现的错误，比如Option冲突
+func (self *Parser) Err() error {
+	if errs := self.definitionErrors(); len(errs) > 0 {
+		return errors.New(errs[0].Message)
+	}
+	return nil
+}
+
+// 定义时发现的错误以及子命令覆盖父命令Option的错误
+// 覆盖的检查依赖Local等设置，在定义完成后检查整个命令树，定义不变时只检查一次
+func (self *Parser) definitionErrors() []Finding {
+	root := self.root()
+	root.mu.RLock()
+	valid := root.shadowErrs != nil && root.shadowGen == root.generation()
+	errs := append(root.findings(), root.shadowErrs...)
+	root.mu.RUnlock()
+	if valid {
+		return errs
+	}
+
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if root.shadowErrs == nil || root.shadowGen != root.generation() {
+		// 默认的long会参与冲突检查
+		parsers := root.walk(nil)
+		for _, p := range parsers {
+			p.preFilterOptions()
+		}
+		root.shadowErrs = []Finding{}
+		for _, p := range root.orderedWalk(nil) {
+			root.shadowErrs = append(root.shadowErrs, p.shadowErrors()...)
+		}
+		root.shadowGen = root.generation()
+	}
+	return append(root.findings(), root.shadowErrs...)
+}
+
+// defErrs的副本
+func (self *Parser) findings() []Finding {
+	self.errMu.Lock()
+	defer self.errMu.Unlock()
+	return append([]Finding{}, self.defErrs...)
+}
+
+// 按声明顺序遍历命令树，不触发延迟构建
+func (self *Parser) orderedWalk(out []*Parser) []*Parser {
+	out = append(out, self)
+	for _, k := range self.subNames {
+		if v, ok := self.Subs[k]; ok && !v.pending() {
+			out = v.orderedWalk(out)
+		}
+	}
+	return out
+}
+
+// 当前命令的Option覆盖父命令中会被继承的Option
+func (self *Parser) shadowErrors() []Finding {
+	out := []Finding{}
+	if self.conflict != ConflictError {
+		return out
+	}
+	for _, k := range self.optNames {
+		o, ok := self.Opts[k]
+		if !ok {
+			continue
+		}
+		for _, name := range o.names() {
+			lookup := func(p *Parser) *Option { return p.LongOpts[strings.TrimPrefix(name, "--")] }
+			if !strings.HasPrefix(name, "--") {
+				lookup = func(p *Parser) *Option { return p.ShortOpts[[]rune(name)[1]] }
+			}
+			// 在同一命令中冲突而没有注册的名称
+			if lookup(self) != o {
+				continue
+			}
+			for p := self.Super; p != nil; p = p.Super {
+				if old := lookup(p); old != nil && old != o && !old.local {
+					out = append(out, Finding{
+						Severity: SeverityError,
+						Location: self.CommandPath(),
+						Message:  fmt.Sprintf("Conflicting option %s: '%s' in %s shadows '%s' in %s", name, o.dest, self.Title, old.dest, p.Title),
+					})
+					break
+				}
+			}
+		}
+	}
+	return out
+}
+
+func (self *Parser) root() *Parser {
+	if self.Root != nil {
+		return self.Root
+	}
+	return self
+}
+
+func (self *Parser) defError(format string, a ...interface{}) {
+	root := self.root()
+	root.errMu.Lock()
+	defer root.errMu.Unlock()
+	root.defErrs = append(root.defErrs, Finding{
+		Severity: SeverityError,
+		Location: self.CommandPath(),
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+// 检查名称（比如 -f、--file）在同一命令中是否冲突，返回true时可以注册该名称
+// 与父命令的冲突在definitionErrors中检查；lookup返回Parser中使用该名称的Option，drop使先定义的Option失去该名称
+func (self *Parser) checkConflict(o *Option, name string, lookup func(p *Parser) *Option, drop func(old *Option)) bool {
+	if old := lookup(self); old != nil && old != o {
+		if self.conflict == ConflictResolve {
+			drop(old)
+			return true
+		}
+		self.defError("Conflicting option %s: '%s' conflicts with '%s' in %s", name, o.dest, old.dest, self.Title)
+		return false
+	}
+
+	return true
+}
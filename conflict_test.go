@@ -0,0 +1,177 @@
+package goargs
+
+import (
+	"testing"
+)
+
+func TestConflictError(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	parser.AddOption("file", "file name").Short('f')
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting option -f: 'file' conflicts with 'force' in root", err.Error())
+	}
+	// 先定义的Option保留该名称
+	assertEqual(t, "force", parser.ShortOpts['f'].dest)
+
+	result := parser.ParseArgs([]string{"-f"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Conflicting option -f: 'file' conflicts with 'force' in root", result.err.Error())
+
+	parser = ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Long("mode")
+	parser.AddOption("mode", "mode type")
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting option 'mode': 'mode' conflicts with 'mode' in root", err.Error())
+	}
+}
+
+func TestConflictShadow(t *testing.T) {
+	// 子命令覆盖父命令的Option
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("file", "file name").Short('f')
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting option -f: 'file' in upload shadows 'force' in root", err.Error())
+	}
+
+	// 先定义子命令，再定义父命令的Option
+	parser = ArgumentParser("app", "help")
+	upload = parser.AddParser("upload", "upload help")
+	part := upload.AddParser("part", "part help")
+	part.AddOption("file", "file name").Long("file")
+	parser.AddOption("config", "config file").Long("file")
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting option --file: 'file' in part shadows 'config' in root", err.Error())
+	}
+
+	// 本地Option不会被继承，不冲突
+	parser = ArgumentParser("app", "help")
+	parser.AddOption("force", "force do something").Local().Short('f').Bool(true)
+	upload = parser.AddParser("upload", "upload help")
+	upload.AddOption("file", "file name").Short('f')
+	if err := parser.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 允许覆盖
+	parser = ArgumentParser("app", "help")
+	parser.SetConflictHandler(ConflictShadow)
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	upload = parser.AddParser("upload", "upload help")
+	upload.AddOption("file", "file name").Short('f')
+	if err := parser.Err(); err != nil {
+		t.Fatal(err)
+	}
+	result := parser.ParseArgs([]string{"upload", "-f", "a.txt"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	file, _ := result.cx.GetString("file")
+	assertEqual(t, "a.txt", file)
+
+	// 同一命令中依然报错
+	parser.AddOption("fast", "fast mode").Short('f')
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting option -f: 'fast' conflicts with 'force' in root", err.Error())
+	}
+}
+
+func TestConflictResolve(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.SetConflictHandler(ConflictResolve)
+	force := parser.AddOption("force", "force do something").Short('f').Long("force").Bool(true)
+	parser.AddOption("file", "file name").Short('f')
+	if err := parser.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, "file", parser.ShortOpts['f'].dest)
+	if force.shortV != 0 {
+		t.Error("Expect the earlier option loses the name")
+	}
+
+	result := parser.ParseArgs([]string{"-f", "a.txt", "--force"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	file, _ := result.cx.GetString("file")
+	assertEqual(t, "a.txt", file)
+}
+
+func TestConflictLocalAfterShort(t *testing.T) {
+	// 先定义子命令的 -f，父命令的Option在Short之后才设置Local
+	parser := ArgumentParser("app", "help")
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("file", "file name").Short('f')
+	parser.AddOption("force", "force do something").Short('f').Bool(true).Local()
+	if err := parser.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 之后改为Persistent，依然能发现冲突
+	parser.Opts["force"].Persistent()
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting option -f: 'file' in upload shadows 'force' in root", err.Error())
+	}
+}
+
+func TestConflictImplicitLong(t *testing.T) {
+	// 默认的long与子命令的long冲突
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("output", "output file")
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("path", "upload path").Long("output")
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting option --output: 'path' in upload shadows 'output' in root", err.Error())
+	}
+}
+
+func TestConflictResolveLong(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.SetConflictHandler(ConflictResolve)
+	parser.AddOption("force", "force do something").Long("force")
+	parser.AddOption("newforce", "force do something").Long("force")
+	// 默认的long让给显式设置的Option
+	parser.AddOption("mode", "mode type")
+	parser.AddOption("newmode", "mode type").Long("mode")
+
+	// 多次编译后，失去名称的Option不会取回该名称
+	for i := 0; i < 2; i++ {
+		parser.changed()
+		if err := parser.Compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertEqual(t, "newforce", parser.LongOpts["force"].dest)
+	assertEqual(t, "newmode", parser.LongOpts["mode"].dest)
+
+	result := parser.ParseArgs([]string{"--force", "x", "--mode", "fast"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	newforce, _ := result.cx.GetString("newforce")
+	assertEqual(t, "x", newforce)
+	newmode, _ := result.cx.GetString("newmode")
+	assertEqual(t, "fast", newmode)
+	if result.cx.IsSet("force") {
+		t.Error("Expect force is not set")
+	}
+}
@@ -1,7 +1,11 @@
 package goargs
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"sort"
+	"time"
 )
 
 // TODO 支持更多的数据类型
@@ -9,13 +13,37 @@ import (
 
 // 主要是用于和用户的方法交互
 type Context struct {
-	options map[string]*Option // 包括了当前Parser以及之上的所有父Pasrser的Option
-	parser  *Parser            // 关联的解析器
+	options map[string]*Option      // 包括了当前Parser以及之上的所有父Pasrser的Option
+	values  map[string]*optionValue // dest - 本次解析的参数值
+	parser  *Parser                 // 关联的解析器
 	err     error
 }
 
+// 获取dest的参数值，多个Option共享dest时，共享同一个参数值
+func (self *Context) value(o *Option) *optionValue {
+	if v, ok := self.options[o.dest]; ok {
+		o = v
+	}
+	if self.values == nil {
+		self.values = map[string]*optionValue{}
+	}
+	v, ok := self.values[o.dest]
+	if !ok {
+		v = &optionValue{option: o}
+		self.values[o.dest] = v
+	}
+	return v
+}
+
+func (self *Context) lookup(dest string) (*optionValue, bool) {
+	if o, ok := self.options[dest]; ok {
+		return self.value(o), true
+	}
+	return nil, false
+}
+
 func (self *Context) GetString(dest string) (v string, err error) {
-	if v, ok := self.options[dest]; ok {
+	if v, ok := self.lookup(dest); ok {
 		return v.getString(), nil
 	}
 	err = errors.New("NotFound")
@@ -23,13 +51,92 @@ func (self *Context) GetString(dest string) (v string, err error) {
 }
 
 func (self *Context) GetBool(dest string) (v bool, err error) {
-	if v, ok := self.options[dest]; ok {
+	if v, ok := self.lookup(dest); ok {
 		return v.getBool(), nil
 	}
 	err = errors.New("NotFound")
 	return
 }
 
+func (self *Context) GetInt(dest string) (v int, err error) {
+	if v, ok := self.lookup(dest); ok {
+		return v.getInt()
+	}
+	err = errors.New("NotFound")
+	return
+}
+
+func (self *Context) GetDuration(dest string) (v time.Duration, err error) {
+	if v, ok := self.lookup(dest); ok {
+		return v.getDuration()
+	}
+	err = errors.New("NotFound")
+	return
+}
+
+func (self *Context) GetStringSlice(dest string) (v []string, err error) {
+	if v, ok := self.lookup(dest); ok {
+		return v.getStringSlice(), nil
+	}
+	err = errors.New("NotFound")
+	return
+}
+
+// 获取带类型的参数值，未设置时为默认值
+func (self *Context) Value(dest string) interface{} {
+	if v, ok := self.lookup(dest); ok {
+		if value, err := v.decode(); err == nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// 设置参数值，用于自定义的Action
+func (self *Context) SetValue(dest string, v interface{}) error {
+	if o, ok := self.lookup(dest); ok {
+		return o.parse(v)
+	}
+	return fmt.Errorf("Unknown dest: '%s'", dest)
+}
+
+// 获取参数值的来源：命令行、环境变量、配置文件、默认值或未设置
+func (self *Context) Source(dest string) Source {
+	if v, ok := self.lookup(dest); ok {
+		return v.getSource()
+	}
+	return Source{Kind: Unset}
+}
+
+// 参数是否由命令行、环境变量或配置文件设置
+func (self *Context) IsSet(dest string) bool {
+	if v, ok := self.lookup(dest); ok {
+		return v.stored
+	}
+	return false
+}
+
+// 输出所有参数的值以及来源，用于 --print-config
+func (self *Context) explain() string {
+	buf := new(bytes.Buffer)
+	dests := []string{}
+	maxlen := 0
+	for k := range self.options {
+		dests = append(dests, k)
+		if len(k) > maxlen {
+			maxlen = len(k)
+		}
+	}
+	sort.Strings(dests)
+
+	f := fmt.Sprintf("%%-%ds = %%s (%%s)", maxlen)
+	for _, k := range dests {
+		v, _ := self.lookup(k)
+		fmt.Fprintln(buf, fmt.Sprintf(f, k, v.getValueString(), v.getSource()))
+	}
+	return buf.String()
+}
+
 func (self *Context) Error(err error) {
 	self.err = err
 }
@@ -0,0 +1,87 @@
+package goargs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDeprecatedOption(t *testing.T) {
+	buf := new(bytes.Buffer)
+	parser := ArgumentParser("app", "help")
+	parser.SetErrOutput(buf)
+	parser.AddOption("output", "output file").Short('o').Long("output")
+	parser.AddOption("out", "output file").Long("out").Dest("output").Deprecated("use --output instead")
+	parser.AddParser("upload", "upload files")
+
+	// 转发到新的Option
+	result := parser.ParseArgs([]string{"upload", "--out", "a.txt"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	output, _ := result.cx.GetString("output")
+	assertEqual(t, "a.txt", output)
+	assertEqual(t, "warning: Option '--out' is deprecated: use --output instead\n", buf.String())
+
+	buf.Reset()
+	result = parser.ParseArgs([]string{"upload", "-o", "a.txt"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "", buf.String())
+}
+
+func TestDeprecatedCommand(t *testing.T) {
+	buf := new(bytes.Buffer)
+	parser := ArgumentParser("app", "help")
+	parser.SetErrOutput(buf)
+	parser.AddParser("upload", "upload files")
+	parser.AddParser("push", "upload files").Deprecated("use upload instead")
+
+	result := parser.ParseArgs([]string{"push"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "push", result.Title)
+	assertEqual(t, "warning: Command 'app push' is deprecated: use upload instead\n", buf.String())
+}
+
+func TestDeprecatedStrict(t *testing.T) {
+	buf := new(bytes.Buffer)
+	parser := ArgumentParser("app", "help")
+	parser.SetErrOutput(buf)
+	parser.SetDeprecatedStrict(true)
+	parser.AddOption("output", "output file").Short('o').Long("output")
+	parser.AddOption("out", "output file").Long("out").Dest("output").Deprecated("use --output instead")
+	parser.AddParser("push", "upload files").Deprecated("use upload instead")
+
+	result := parser.ParseArgs([]string{"push"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Command 'app push' is deprecated: use upload instead", result.err.Error())
+
+	result = parser.ParseArgs([]string{"--out", "a.txt"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Option '--out' is deprecated: use --output instead", result.err.Error())
+	assertEqual(t, "", buf.String())
+}
+
+func TestDeprecatedHelp(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("output", "output file").Short('o').Long("output")
+	parser.AddOption("out", "output file").Long("out").Dest("output").Deprecated("use --output instead")
+	parser.AddParser("upload", "upload files")
+	parser.AddParser("push", "upload files").Deprecated("use upload instead")
+
+	usage, _ := parser.Usage()
+	if strings.Contains(usage, "--out ") || strings.Contains(usage, "push") {
+		t.Error(usage)
+	}
+	usage, _ = parser.usage(true)
+	if !strings.Contains(usage, "--out ") || !strings.Contains(usage, "push") {
+		t.Error(usage)
+	}
+}
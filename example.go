@@ -0,0 +1,185 @@
+package goargs
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// 用法示例，比如 Example{"app upload -f a.txt", "upload a.txt to the cloud"}
+type Example struct {
+	Cmdline     string // 完整的命令行
+	Explanation string // 示例的说明，可以为空
+}
+
+// 子命令列表中的简短描述，默认使用AddParser时的help
+func (self *Parser) Short(s string) *Parser {
+	self.short = s
+	return self
+}
+
+// 帮助信息开头的详细描述，默认使用AddParser时的help
+func (self *Parser) Long(s string) *Parser {
+	self.long = s
+	return self
+}
+
+// 帮助信息结尾的说明，比如文档链接
+func (self *Parser) Epilog(s string) *Parser {
+	self.epilog = s
+	return self
+}
+
+// 添加用法示例，在帮助信息的Examples一节中按添加顺序显示
+func (self *Parser) Example(cmdline string, explanation string) *Parser {
+	self.examples = append(self.examples, Example{Cmdline: cmdline, Explanation: explanation})
+	return self
+}
+
+func (self *Parser) summary() string {
+	if self.short != "" {
+		return self.short
+	}
+	return self.Help
+}
+
+func (self *Parser) description() string {
+	if self.long != "" {
+		return self.long
+	}
+	return self.Help
+}
+
+func (self *Parser) ExampleText() string {
+	return self.exampleText(helpStyle{})
+}
+
+// 每个示例的命令行之后是缩进的说明：
+//
+//	app upload -f a.txt
+//	    upload a.txt to the cloud
+func (self *Parser) exampleText(s helpStyle) string {
+	buf := new(bytes.Buffer)
+	width := s.lineWidth() - 6
+	if width < minHelpWidth {
+		width = minHelpWidth
+	}
+	for i, v := range self.examples {
+		if i > 0 {
+			fmt.Fprintln(buf)
+		}
+		fmt.Fprintf(buf, "  %s\n", v.Cmdline)
+		if v.Explanation == "" {
+			continue
+		}
+		for _, line := range wrapText(v.Explanation, width) {
+			fmt.Fprintf(buf, "%s%s\n", strings.Repeat(" ", 6), line)
+		}
+	}
+	return buf.String()
+}
+
+// 检查整个命令树中的示例，每个示例通过ParseArgs解析，不执行Handler，可以在单元测试中调用：
+//
+//	for _, f := range root.VerifyExamples() {
+//		t.Error(f)
+//	}
+//
+// 示例以程序名开头，比如 app upload -f a.txt；解析出错，或者匹配的命令不是示例所属的命令及其子命令时报错
+// 注意：StringVar等绑定的变量会被写入
+func (self *Parser) VerifyExamples() []Finding {
+	findings := []Finding{}
+	for _, p := range self.walkAll(nil) {
+		for _, v := range p.examples {
+			if err := p.verifyExample(v.Cmdline); err != nil {
+				findings = append(findings, Finding{
+					Severity: SeverityError,
+					Location: p.CommandPath(),
+					Message:  fmt.Sprintf("example '%s': %s", v.Cmdline, err),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// 与walk相同，延迟构建的子命令会被构建
+func (self *Parser) walkAll(out []*Parser) []*Parser {
+	self.build()
+	out = append(out, self)
+	for _, v := range self.orderedSubs() {
+		out = v.walkAll(out)
+	}
+	return out
+}
+
+func (self *Parser) verifyExample(cmdline string) error {
+	root := self.root()
+	args, err := splitCmdline(cmdline)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 || args[0] != root.Name {
+		return fmt.Errorf("expect the example starts with '%s'", root.Name)
+	}
+
+	result := root.ParseArgs(args[1:])
+	if result.err == ERR_NotFound {
+		return fmt.Errorf("unknown command")
+	}
+	if result.err != nil && result.err != ERR_Usage {
+		return result.err
+	}
+	// 内置的help子命令
+	if result.parser == nil {
+		return nil
+	}
+	for p := result.parser; p != self; p = p.Super {
+		if p == nil {
+			return fmt.Errorf("runs '%s' instead of '%s'", result.parser.CommandPath(), self.CommandPath())
+		}
+	}
+	return nil
+}
+
+// 按shell的规则拆分命令行，支持单引号、双引号以及反斜杠转义
+func splitCmdline(s string) ([]string, error) {
+	args := []string{}
+	buf := new(strings.Builder)
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			buf.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				buf.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, buf.String())
+				buf.Reset()
+				inArg = false
+			}
+		default:
+			buf.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, buf.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/red-chen/goargs"
+	"os"
+)
+
+type Upload struct {
+	File string `goargs:"--file,required" help:"file name"`
+}
+
+func (self *Upload) Run(c *goargs.Context) error {
+	fmt.Println(self.File)
+	return nil
+}
+
+type Download struct {
+	Out string `goargs:"--out,required" help:"file name"`
+}
+
+func (self *Download) Run(c *goargs.Context) error {
+	fmt.Println(self.Out)
+
+	if len(self.Out) < 3 {
+		return errors.New("The output file len less than 3.")
+	}
+	return nil
+}
+
+type App struct {
+	Mode     string   `goargs:"-m,--mode" default:"test" help:"testing mode"`
+	Config   string   `goargs:"-c,--config" default:"/tmp/config.json" help:"config file"`
+	Force    bool     `goargs:"-f" help:"force do something"`
+	Upload   Upload   `goargs:"command" help:"Upload file to cloud"`
+	Download Download `goargs:"command" help:"Download file from cloud"`
+}
+
+func (self *App) Run(c *goargs.Context) error {
+	fmt.Println(self.Mode)
+	fmt.Println(self.Config)
+	fmt.Println(self.Force)
+	return nil
+}
+
+func main() {
+	parser, err := goargs.StructParser("app", "Sample tool for goargs", &App{})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	result := parser.ParseArgs(os.Args[1:])
+	result.HandleError()
+}
@@ -0,0 +1,105 @@
+package goargs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExamples(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	upload := parser.AddParser("upload", "upload files").
+		Short("upload files to the cloud").
+		Long(`
+			Upload local files to the cloud storage.
+
+			Large files are uploaded in parts.
+		`).
+		Epilog("See https://example.com/upload for more details.").
+		Example("app upload --file /backup a.txt", "upload a.txt to /backup").
+		Example("app upload --file /backup *.log", "")
+	upload.AddOption("file", "remote file").Long("file")
+
+	assertEqual(t, "upload upload files to the cloud\n", parser.SubCommandText())
+
+	usage, _ := upload.Usage()
+	assertEqual(t, ""+
+		"Upload local files to the cloud storage.\n"+
+		"\n"+
+		"Large files are uploaded in parts.\n"+
+		"\n"+
+		"Usage:\n"+
+		"    app upload [--file FILE] \n"+
+		"\n"+
+		"Options:\n"+
+		"      --file remote file\n"+
+		"  \n"+
+		"Examples:\n"+
+		"  app upload --file /backup a.txt\n"+
+		"      upload a.txt to /backup\n"+
+		"\n"+
+		"  app upload --file /backup *.log\n"+
+		"\n"+
+		"See https://example.com/upload for more details.\n"+
+		"\n", usage)
+
+	// 模板中可以使用示例以及简短描述
+	upload.SetUsageTemplate(`{{ summary .Parser }}{{ range examples . }}|{{ .Cmdline }}{{ end }}`)
+	usage, _ = upload.Usage()
+	assertEqual(t, "upload files to the cloud|app upload --file /backup a.txt|app upload --file /backup *.log", usage)
+}
+
+func TestVerifyExamples(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.Example("app upload --file /x 'a b.txt'", "quoted argument")
+	parser.Example("app help upload", "")
+	upload := parser.AddParser("upload", "upload files").
+		Example("app upload --file /backup a.txt", "upload a.txt to /backup").
+		Example("app upload --file /backup *.log", "")
+	upload.AddOption("file", "remote file").Long("file")
+	upload.AddArgument("src", "local files").StringSliceVar(new([]string)).Default([]string{})
+	upload.SetDefaults(func(c *Context) {})
+	parser.AddParser("download", "download files").SetDefaults(func(c *Context) {})
+	parser.AddLazyParser("sync", "sync files", func(p *Parser) {
+		p.AddOption("dir", "local dir").Long("dir").Required()
+		p.Example("app sync", "missing --dir")
+	})
+	if findings := parser.VerifyExamples(); len(findings) != 1 {
+		t.Fatal(findings)
+	} else {
+		assertEqual(t, "error: app sync: example 'app sync': Missing required option: '--dir'", findings[0].String())
+	}
+
+	handled := false
+	parser.Subs["upload"].SetDefaults(func(c *Context) { handled = true })
+	parser.Subs["upload"].
+		Example("app upload --fil /x", "").
+		Example("app download", "").
+		Example("app uplaod --file /x", "").
+		Example("upload --file /x", "").
+		Example("app upload 'a.txt", "")
+
+	expect := []string{
+		"error: app upload: example 'app upload --fil /x': Unrecognized arguments: --fil",
+		"error: app upload: example 'app download': runs 'app download' instead of 'app upload'",
+		"error: app upload: example 'app uplaod --file /x': unknown command",
+		"error: app upload: example 'upload --file /x': expect the example starts with 'app'",
+		"error: app upload: example 'app upload 'a.txt': unterminated quote or escape",
+		"error: app sync: example 'app sync': Missing required option: '--dir'",
+	}
+	findings := parser.VerifyExamples()
+	assertEqualInt(t, len(expect), len(findings))
+	for i, f := range findings {
+		assertEqual(t, expect[i], f.String())
+	}
+	if handled {
+		t.Error("Expect the handler is not called")
+	}
+}
+
+func TestSplitCmdline(t *testing.T) {
+	args, err := splitCmdline(`app  upload -m "fast mode" 'a b' c\ d ""`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "app|upload|-m|fast mode|a b|c d|", strings.Join(args, "|"))
+}
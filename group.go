@@ -0,0 +1,102 @@
+package goargs
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// Option分组，帮助信息中每个分组单独显示一节
+type Group struct {
+	Name   string
+	Help   string
+	parser *Parser
+	order  int
+}
+
+// 添加分组，同名的分组只添加一次；分组按声明顺序显示，没有Option的分组不显示
+func (self *Parser) AddGroup(name string, help string) *Group {
+	for _, g := range self.groups {
+		if g.Name == name {
+			return g
+		}
+	}
+	g := &Group{Name: name, Help: help, parser: self}
+	self.groups = append(self.groups, g)
+	return g
+}
+
+// 在分组中添加Option
+func (self *Group) AddOption(dest string, help string) *Option {
+	return self.parser.AddOption(dest, help).Group(self)
+}
+
+// 设置分组的顺序，数值小的在前，相同时按声明顺序
+func (self *Group) Order(n int) *Group {
+	self.order = n
+	return self
+}
+
+// 将Option加入分组
+func (self *Option) Group(g *Group) *Option {
+	self.group = g
+	return self
+}
+
+// 设置子命令的分类，SubCommandText按分类显示，比如 Category("Management Commands")
+func (self *Parser) Category(name string) *Parser {
+	self.category = name
+	return self
+}
+
+func (self *Parser) orderedGroups() []*Group {
+	groups := append([]*Group{}, self.groups...)
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].order < groups[j].order
+	})
+	return groups
+}
+
+// 未分组的Option在前，之后每个分组一节
+func groupedOptionText(opts []*Option, groups []*Group, s helpStyle) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString(optionDetailText(groupOptions(opts, nil), s))
+	for _, g := range groups {
+		members := groupOptions(opts, g)
+		if len(members) == 0 {
+			continue
+		}
+		if buf.Len() > 0 {
+			fmt.Fprintln(buf)
+		}
+		fmt.Fprintf(buf, "%s:\n", g.Name)
+		if g.Help != "" {
+			fmt.Fprintln(buf, g.Help)
+		}
+		buf.WriteString(optionDetailText(members, s))
+	}
+	return buf.String()
+}
+
+func groupOptions(opts []*Option, g *Group) []*Option {
+	out := []*Option{}
+	for _, v := range opts {
+		if v.group == g {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// 子命令的分类，按第一次出现的顺序，未分类的子命令在前
+func subCategories(subs []*Parser) []string {
+	out := []string{""}
+	seen := map[string]bool{"": true}
+	for _, v := range subs {
+		if !seen[v.category] {
+			seen[v.category] = true
+			out = append(out, v.category)
+		}
+	}
+	return out
+}
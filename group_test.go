@@ -0,0 +1,81 @@
+package goargs
+
+import (
+	"testing"
+)
+
+func TestOptionGroup(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode")
+	network := parser.AddGroup("Network", "Options controlling connections")
+	network.AddOption("host", "server host").Long("host")
+	network.AddOption("port", "server port").Short('p').Long("port")
+	parser.AddGroup("Empty", "no options")
+	output := parser.AddGroup("Output", "")
+	parser.AddOption("format", "output format").Long("format").Group(output)
+
+	expect := "" +
+		"  -m, --mode testing mode\n" +
+		"\n" +
+		"Network:\n" +
+		"Options controlling connections\n" +
+		"      --host server host\n" +
+		"  -p, --port server port\n" +
+		"\n" +
+		"Output:\n" +
+		"      --format output format\n"
+	assertEqual(t, expect, parser.OptionDetailText())
+
+	// 分组的顺序
+	output.Order(-1)
+	expect = "" +
+		"  -m, --mode testing mode\n" +
+		"\n" +
+		"Output:\n" +
+		"      --format output format\n" +
+		"\n" +
+		"Network:\n" +
+		"Options controlling connections\n" +
+		"      --host server host\n" +
+		"  -p, --port server port\n"
+	assertEqual(t, expect, parser.OptionDetailText())
+
+	result := parser.ParseArgs([]string{"--host", "localhost"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	host, _ := result.cx.GetString("host")
+	assertEqual(t, "localhost", host)
+}
+
+func TestOptionSetGroup(t *testing.T) {
+	set := NewOptionSet()
+	set.AddGroup("Network", "").AddOption("host", "server host").Long("host")
+
+	parser := ArgumentParser("app", "help")
+	if err := parser.AddOptionSet(set); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "Network:\n      --host server host\n", parser.OptionDetailText())
+}
+
+func TestSubCommandCategory(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddParser("run", "run a container")
+	parser.AddParser("container", "manage containers").Category("Management Commands")
+	parser.AddParser("image", "manage images").Category("Management Commands")
+	parser.AddParser("login", "log in to a registry").Category("Registry Commands")
+	parser.AddParser("ps", "list containers")
+
+	expect := "" +
+		"run       run a container\n" +
+		"ps        list containers\n" +
+		"\n" +
+		"Management Commands:\n" +
+		"container manage containers\n" +
+		"image     manage images\n" +
+		"\n" +
+		"Registry Commands:\n" +
+		"login     log in to a registry\n"
+	assertEqual(t, expect, parser.SubCommandText())
+}
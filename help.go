@@ -0,0 +1,392 @@
+package goargs
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 默认的帮助信息模板
+const UsageTemplate = `{{ .Description }}
+
+Usage:
+    {{ .CommandPath }} {{ .OptionText }} {{ with .OptionDetailText}}
+
+Options:
+{{.}}{{end}}{{ with .ArgumentText}}
+{{ if not $.OptionDetailText }}
+{{ end }}Arguments:
+{{.}}{{end}} {{ with .GlobalOptionDetailText}}
+Global Options:
+{{.}}{{end}} {{ with .SubCommandText}}
+SubCommands:
+{{.}}{{end}}{{ with .ExampleText}}
+Examples:
+{{.}}{{end}}{{ with .EpilogText}}
+{{.}}
+{{end}}
+`
+
+// 生成帮助信息，可以通过SetHelpFormatter替换
+type HelpFormatter interface {
+	Format(data HelpData) (string, error)
+}
+
+// 传给HelpFormatter以及模板的数据
+type HelpData struct {
+	*Parser
+	All bool // 包括隐藏的子命令和Option，--help-all 时为true
+
+	style helpStyle
+}
+
+// 帮助信息的渲染选项
+type helpStyle struct {
+	all           bool // 包括隐藏的子命令和Option
+	showDefaults  bool // 显示默认值
+	metavarByType bool // 占位符使用参数值的类型
+	dedent        bool // 去掉描述的公共缩进以及首尾空行
+	width         int  // 帮助信息的宽度，为0时使用终端宽度
+	showRequired  bool // 显示 [required]
+	showEnv       bool // 显示 [env: APP_MODE]
+	showChoices   bool // 显示 {a,b,c}
+	showType      bool // 显示 [type: int]
+
+	redact func(dest string, value string) string // 处理敏感Option的默认值
+}
+
+// 基于text/template的HelpFormatter，各个开关对应argparse中不同的formatter_class
+type TemplateFormatter struct {
+	Template       string           // 为空时使用UsageTemplate
+	RawDescription bool             // 原样输出描述，否则去掉公共缩进以及首尾空行
+	ShowDefaults   bool             // 在Option的帮助信息后显示默认值
+	MetavarByType  bool             // 占位符使用参数值的类型，比如 INT，否则使用dest
+	Width          int              // 帮助信息的宽度，为0时使用环境变量COLUMNS，默认80
+	Funcs          template.FuncMap // 额外的模板函数，可以覆盖HelpFuncs中的函数
+	ShowRequired   bool             // 必选参数显示 [required]
+	ShowEnv        bool             // 显示对应的环境变量，比如 [env: APP_MODE]
+	ShowChoices    bool             // 显示可选的参数值，比如 {fast,slow}
+	ShowType       bool             // 显示参数值的类型，比如 [type: int]
+
+	// 处理Secret的Option的默认值，返回值替代默认值显示，为空时不显示；未设置时显示为 ******
+	Redact func(dest string, value string) string
+}
+
+func (self *TemplateFormatter) Format(data HelpData) (string, error) {
+	tmpl := self.Template
+	if tmpl == "" {
+		tmpl = UsageTemplate
+	}
+	t, err := template.New("usage").Funcs(HelpFuncs()).Funcs(self.Funcs).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	data.style = helpStyle{
+		all:           data.All,
+		showDefaults:  self.ShowDefaults,
+		metavarByType: self.MetavarByType,
+		dedent:        !self.RawDescription,
+		width:         self.Width,
+		showRequired:  self.ShowRequired,
+		showEnv:       self.ShowEnv,
+		showChoices:   self.ShowChoices,
+		showType:      self.ShowType,
+		redact:        self.Redact,
+	}
+
+	b := new(bytes.Buffer)
+	if err = t.Execute(b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// 设置当前Parser以及子命令的HelpFormatter，子命令可以单独设置
+func (self *Parser) SetHelpFormatter(f HelpFormatter) {
+	self.formatter = f
+}
+
+// 设置当前Parser以及子命令的帮助信息模板
+func (self *Parser) SetUsageTemplate(tmpl string) {
+	self.formatter = &TemplateFormatter{Template: tmpl}
+}
+
+// 向上查找最近设置的HelpFormatter
+func (self *Parser) helpFormatter() HelpFormatter {
+	for p := self; p != nil; p = p.Super {
+		if p.formatter != nil {
+			return p.formatter
+		}
+	}
+	return &TemplateFormatter{}
+}
+
+// 帮助信息，不包括隐藏的子命令和Option
+func (self *Parser) Usage() (string, error) {
+	return self.usage(false)
+}
+
+func (self *Parser) usage(all bool) (string, error) {
+	self.build()
+	// 设置默认的long，与解析时一致
+	self.compile()
+	return self.helpFormatter().Format(HelpData{Parser: self, All: all})
+}
+
+// 输出帮助信息，返回ERR_Usage
+func (self *Parser) printUsage(all bool) error {
+	s, err := self.usage(all)
+	if err != nil {
+		return err
+	}
+	fmt.Println(s)
+	return ERR_Usage
+}
+
+// 执行内置的help子命令，返回ERR_Usage
+func (self *Parser) printHelpCommand(cmds []string, params []string) error {
+	s, err := self.helpCommand(cmds, params)
+	if err != nil {
+		return err
+	}
+	fmt.Println(s)
+	return ERR_Usage
+}
+
+// 命令的描述
+func (self HelpData) Description() string {
+	if self.style.dedent {
+		return dedent(self.description())
+	}
+	return self.description()
+}
+
+func (self HelpData) ExampleText() string {
+	return self.exampleText(self.style)
+}
+
+// 帮助信息结尾的说明
+func (self HelpData) EpilogText() string {
+	if self.style.dedent {
+		return dedent(self.epilog)
+	}
+	return self.epilog
+}
+
+func (self HelpData) OptionText() string {
+	return self.optionText(self.style)
+}
+
+func (self HelpData) ArgumentText() string {
+	return self.argumentText(self.style)
+}
+
+func (self HelpData) OptionDetailText() string {
+	return self.optionDetailText(self.style)
+}
+
+func (self HelpData) GlobalOptionDetailText() string {
+	return self.globalOptionDetailText(self.style)
+}
+
+func (self HelpData) SubCommandText() string {
+	return self.subCommandText(self.style)
+}
+
+// 帮助信息模板中可以使用的函数，比如：
+//
+//	{{ range options . }}{{ flags . }} {{ help . }}{{ with default $ . }} (default: {{ . }}){{ end }}
+//	{{ end }}
+func HelpFuncs() template.FuncMap {
+	return template.FuncMap{
+		// 当前命令的Option
+		"options": func(d HelpData) []*Option {
+			return visibleOptions(d.orderedOptions(), d.All)
+		},
+		// 继承自父命令的Option
+		"globalOptions": func(d HelpData) []*Option {
+			return visibleOptions(d.globalOptions(), d.All)
+		},
+		// 包含Option的分组
+		"groups": func(d HelpData) []*Group {
+			out := []*Group{}
+			opts := visibleOptions(d.orderedOptions(), d.All)
+			for _, g := range d.orderedGroups() {
+				if len(groupOptions(opts, g)) > 0 {
+					out = append(out, g)
+				}
+			}
+			return out
+		},
+		// 分组中的Option
+		"groupOptions": func(d HelpData, g *Group) []*Option {
+			return groupOptions(visibleOptions(d.orderedOptions(), d.All), g)
+		},
+		// 子命令
+		"subcommands": func(d HelpData) []*Parser {
+			out := []*Parser{}
+			for _, v := range d.orderedSubs() {
+				if d.All || !v.isHidden() {
+					out = append(out, v)
+				}
+			}
+			return out
+		},
+		// 用法示例
+		"examples": func(d HelpData) []Example { return d.examples },
+		// 子命令的简短描述
+		"summary": (*Parser).summary,
+		"flags":   (*Option).flags,
+		"help":    func(o *Option) string { return o.help },
+		// 默认值以及占位符使用当前HelpFormatter的设置，比如 {{ default $ . }}
+		"default":  func(d HelpData, o *Option) string { return o.displayDefault(d.style) },
+		"env":      func(o *Option) string { return o.envV },
+		"choices":  func(o *Option) []string { return o.choices },
+		"required": func(o *Option) bool { return o.requiredV },
+		"metavar":  func(d HelpData, o *Option) string { return o.metavar(d.style) },
+		"type":     (*Option).typeName,
+	}
+}
+
+// 比如 -m, --mode
+func (self *Option) flags() string {
+	out := []string{}
+	if self.shortV != 0 {
+		out = append(out, fmt.Sprintf("-%c", self.shortV))
+	}
+	if self.longV != "" {
+		out = append(out, "--"+self.longV)
+	} else if self.shortV == 0 {
+		out = append(out, "--"+self.name)
+	}
+	return strings.Join(out, ", ")
+}
+
+// 默认值，没有默认值时为空
+func (self *Option) defaultString() string {
+	if self.defValue == nil {
+		return ""
+	}
+	switch v := self.defValue.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// 帮助信息中显示的默认值，敏感Option的默认值经过处理
+func (self *Option) displayDefault(s helpStyle) string {
+	d := self.defaultString()
+	if !self.secret || d == "" {
+		return d
+	}
+	if s.redact != nil {
+		return s.redact(self.dest, d)
+	}
+	return redactValue(d)
+}
+
+// 默认的处理方式，不显示原值
+func redactValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "******"
+}
+
+// 参数值的类型，比如 int、duration
+func (self *Option) typeName() string {
+	if self.setBool {
+		return "bool"
+	}
+	t := self.valueType
+	if t == nil && self.target != nil {
+		t = reflect.TypeOf(self.target).Elem()
+	}
+	if t == nil {
+		return "string"
+	}
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return "duration"
+	}
+	if t.Name() != "" {
+		return strings.ToLower(t.Name())
+	}
+	return t.String()
+}
+
+// 参数值的占位符
+func (self *Option) metavar(s helpStyle) string {
+	if self.metavarV != "" {
+		return self.metavarV
+	}
+	if s.metavarByType {
+		return strings.ToUpper(self.typeName())
+	}
+	return strings.ToUpper(self.dest)
+}
+
+// 详细帮助信息中Option的说明
+// 比如 mode type (default: test) [required] [env: APP_MODE] {fast,slow} [type: string]
+func (self *Option) detailHelp(s helpStyle) string {
+	out := []string{}
+	if self.help != "" {
+		out = append(out, self.help)
+	}
+	if s.showDefaults && !self.setBool {
+		if d := self.displayDefault(s); d != "" {
+			out = append(out, fmt.Sprintf("(default: %s)", d))
+		}
+	}
+	if s.showRequired && self.requiredV {
+		out = append(out, "[required]")
+	}
+	if s.showEnv && self.envV != "" {
+		out = append(out, fmt.Sprintf("[env: %s]", self.envV))
+	}
+	if s.showChoices && len(self.choices) > 0 {
+		out = append(out, fmt.Sprintf("{%s}", strings.Join(self.choices, ",")))
+	}
+	if s.showType && !self.setBool {
+		out = append(out, fmt.Sprintf("[type: %s]", self.typeName()))
+	}
+	return strings.Join(out, " ")
+}
+
+// 去掉公共缩进以及首尾空行
+func dedent(s string) string {
+	lines := strings.Split(s, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		} else {
+			lines[i] = strings.TrimLeft(line, " \t")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
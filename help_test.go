@@ -0,0 +1,161 @@
+package goargs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsageNotEscaped(t *testing.T) {
+	parser := ArgumentParser("app", "Copy <src> to <dst> & more")
+	parser.AddOption("file", "file name, like <file>").Long("file")
+	usage, err := parser.Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(usage, "Copy <src> to <dst> & more\n") || !strings.Contains(usage, "like <file>") {
+		t.Error(usage)
+	}
+}
+
+func TestUsageTemplateError(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.SetUsageTemplate("{{ .Missing }}")
+	result := parser.ParseArgs([]string{"-h"})
+	if result.err == nil || result.err == ERR_Usage {
+		t.Fatal(result.err)
+	}
+
+	parser.SetUsageTemplate("{{ .Name ")
+	if _, err := parser.Usage(); err == nil {
+		t.Fatal()
+	}
+}
+
+func TestUsageTemplateOverride(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode").Default("test").Env("APP_MODE")
+	upload := parser.AddParser("upload", "upload files")
+	upload.AddOption("path", "upload path").Short('p')
+	upload.AddParser("part", "upload part")
+
+	upload.SetUsageTemplate(`{{ .Name }}:{{ range options . }} {{ flags . }}{{ end }}
+{{ range globalOptions . }}{{ flags . }} {{ help . }} [{{ default $ . }}] [{{ env . }}] {{ metavar $ . }} {{ type . }}{{ end }}
+{{ range subcommands . }}{{ .Name }}{{ end }}`)
+
+	usage, err := upload.Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "upload: -p\n-m, --mode testing mode [test] [APP_MODE] MODE string\npart", usage)
+
+	// 子命令继承父命令的设置，父命令不受影响
+	usage, _ = upload.Subs["part"].Usage()
+	assertEqual(t, "part:\n-p upload path [] [] PATH string-m, --mode testing mode [test] [APP_MODE] MODE string\n", usage)
+	usage, _ = parser.Usage()
+	if !strings.HasPrefix(usage, "help\n\nUsage:") {
+		t.Error(usage)
+	}
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	parser := ArgumentParser("app", `
+		Sample tool for goargs
+
+		  - upload files
+	`)
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode").Default("test")
+	Add[int](parser, "retry", "retry count").Long("retry").Default(3)
+	parser.AddOption("timeout", "timeout").Long("timeout").DurationVar(new(time.Duration)).Required()
+
+	usage, _ := parser.Usage()
+	assertEqual(t, ""+
+		"Sample tool for goargs\n"+
+		"\n"+
+		"  - upload files\n"+
+		"\n"+
+		"Usage:\n"+
+		"    app [-m/--mode MODE] [--retry RETRY] --timeout TIMEOUT \n"+
+		"\n"+
+		"Options:\n"+
+		"  -m, --mode    testing mode\n"+
+		"      --retry   retry count\n"+
+		"      --timeout timeout\n"+
+		"  \n", usage)
+
+	parser.SetHelpFormatter(&TemplateFormatter{
+		RawDescription: true,
+		ShowDefaults:   true,
+		MetavarByType:  true,
+	})
+	usage, _ = parser.Usage()
+	assertEqual(t, ""+
+		"\n\t\tSample tool for goargs\n"+
+		"\n"+
+		"\t\t  - upload files\n"+
+		"\t\n"+
+		"\n"+
+		"Usage:\n"+
+		"    app [-m/--mode STRING] [--retry INT] --timeout DURATION \n"+
+		"\n"+
+		"Options:\n"+
+		"  -m, --mode    testing mode (default: test)\n"+
+		"      --retry   retry count (default: 3)\n"+
+		"      --timeout timeout\n"+
+		"  \n", usage)
+}
+
+func TestOptionAnnotations(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode").Default("test").Env("APP_MODE").Choices("test", "prod")
+	parser.AddOption("out", "output file").Long("out").Required()
+	Add[int](parser, "retry", "retry count").Long("retry").Default(3)
+	parser.AddOption("password", "login password").Long("password").Default("123456").Secret()
+	parser.AddOption("force", "force").Short('f').Bool(true)
+
+	formatter := &TemplateFormatter{Template: "{{ .OptionDetailText }}", Width: 120}
+	parser.SetHelpFormatter(formatter)
+	usage, _ := parser.Usage()
+	assertEqual(t, ""+
+		"  -m, --mode     testing mode\n"+
+		"      --out      output file\n"+
+		"      --retry    retry count\n"+
+		"      --password login password\n"+
+		"  -f             force\n", usage)
+
+	formatter.ShowDefaults = true
+	formatter.ShowRequired = true
+	formatter.ShowEnv = true
+	formatter.ShowChoices = true
+	formatter.ShowType = true
+	usage, _ = parser.Usage()
+	assertEqual(t, ""+
+		"  -m, --mode     testing mode (default: test) [env: APP_MODE] {test,prod} [type: string]\n"+
+		"      --out      output file [required] [type: string]\n"+
+		"      --retry    retry count (default: 3) [type: int]\n"+
+		"      --password login password (default: ******) [type: string]\n"+
+		"  -f             force\n", usage)
+
+	// 每一项可以单独开关
+	formatter.ShowType = false
+	formatter.ShowEnv = false
+	formatter.Redact = func(dest string, value string) string { return "<" + dest + ">" }
+	usage, _ = parser.Usage()
+	assertEqual(t, ""+
+		"  -m, --mode     testing mode (default: test) {test,prod}\n"+
+		"      --out      output file [required]\n"+
+		"      --retry    retry count (default: 3)\n"+
+		"      --password login password (default: <password>)\n"+
+		"  -f             force\n", usage)
+
+	// 模板中的函数使用相同的设置
+	formatter.Template = `{{ range options . }}{{ default $ . }} {{ metavar $ . }}|{{ end }}`
+	formatter.MetavarByType = true
+	usage, _ = parser.Usage()
+	assertEqual(t, "test STRING| STRING|3 INT|<password> STRING| BOOL|", usage)
+
+	// 模板中的default函数同样不显示原值
+	parser.SetUsageTemplate(`{{ range options . }}{{ default $ . }}|{{ end }}`)
+	usage, _ = parser.Usage()
+	assertEqual(t, "test||3|******||", usage)
+}
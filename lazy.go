@@ -0,0 +1,31 @@
+package goargs
+
+// 延迟构建子命令，builder只在该子命令被选中，或者需要输出帮助信息时执行一次
+// 父命令的SubCommandText只使用help，不会触发构建
+// builder中可以调用Err、Compile，但不能调用p的Usage、Validate，它们会等待p的构建完成
+func (self *Parser) AddLazyParser(cmd string, help string, builder func(p *Parser)) *Parser {
+	p := self.AddParser(cmd, help)
+	p.builder = builder
+	return p
+}
+
+// 执行延迟构建，并发调用时只执行一次
+// builder在锁外执行，构建完成之前遍历命令树时跳过该子命令
+func (self *Parser) build() {
+	if self.builder == nil {
+		return
+	}
+	self.once.Do(func() {
+		self.builder(self)
+		root := self.root()
+		root.mu.Lock()
+		self.built = true
+		root.mu.Unlock()
+		self.changed()
+	})
+}
+
+// 延迟构建的子命令尚未完成构建，需要持有root.mu
+func (self *Parser) pending() bool {
+	return self.builder != nil && !self.built
+}
@@ -0,0 +1,145 @@
+package goargs
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLazyParser(t *testing.T) {
+	count := 0
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Short('m')
+	parser.AddLazyParser("schema", "schema help", func(p *Parser) {
+		count++
+		p.AddOption("table", "table name").Long("table").Required()
+		p.SetDefaults(func(c *Context) {})
+	})
+	download := parser.AddParser("download", "download help")
+	download.SetDefaults(func(c *Context) {})
+
+	// 选中其他子命令、输出子命令列表时不构建
+	if result := parser.ParseArgs([]string{"download", "-m", "test"}); result.err != nil {
+		t.Fatal(result.err)
+	}
+	if !strings.Contains(parser.SubCommandText(), "schema   schema help") {
+		t.Error(parser.SubCommandText())
+	}
+	assertEqualInt(t, 0, count)
+
+	result := parser.ParseArgs([]string{"schema", "--table", "users"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	table, _ := result.cx.GetString("table")
+	assertEqual(t, "users", table)
+	assertEqualInt(t, 1, count)
+
+	// 只构建一次
+	result = parser.ParseArgs([]string{"schema", "-m", "test"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Missing required option: '--table'", result.err.Error())
+	assertEqualInt(t, 1, count)
+}
+
+func TestLazyParserHelp(t *testing.T) {
+	count := 0
+	builder := func(p *Parser) {
+		count++
+		p.AddOption("table", "table name").Long("table").Required()
+	}
+	parser := ArgumentParser("app", "help")
+	parser.AddLazyParser("schema", "schema help", builder)
+	if usage, _ := parser.Subs["schema"].Usage(); !strings.Contains(usage, "--table") {
+		t.Error(usage)
+	}
+	assertEqualInt(t, 1, count)
+
+	count = 0
+	parser = ArgumentParser("app", "help")
+	parser.AddLazyParser("schema", "schema help", builder)
+	parser.Validate()
+	assertEqualInt(t, 1, count)
+}
+
+func TestLazyParserConcurrent(t *testing.T) {
+	count := 0
+	parser := ArgumentParser("app", "help")
+	parser.AddLazyParser("schema", "schema help", func(p *Parser) {
+		count++
+		p.AddOption("table", "table name").Long("table").Required()
+		p.SetDefaults(func(c *Context) {})
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := parser.ParseArgs([]string{"schema", "--table", "users"})
+			if result.err != nil {
+				t.Error(result.err)
+			}
+		}()
+	}
+	wg.Wait()
+	assertEqualInt(t, 1, count)
+}
+
+func TestLazyParserBuilderErr(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Short('m')
+	var buildErr error
+	parser.AddLazyParser("schema", "schema help", func(p *Parser) {
+		p.AddOption("table", "table name").Short('m')
+		// 构建时可以检查定义，不会死锁
+		if err := p.Compile(); err != nil {
+			t.Error(err)
+		}
+		buildErr = p.Err()
+	})
+
+	result := parser.ParseArgs([]string{"schema"})
+	if buildErr != nil {
+		t.Error(buildErr)
+	}
+	// 构建完成后检查覆盖父命令的Option
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Conflicting option -m: 'table' in schema shadows 'mode' in root", result.err.Error())
+}
+
+func TestLazyParserConcurrentPaths(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Long("mode")
+	parser.AddLazyParser("schema", "schema help", func(p *Parser) {
+		for _, name := range []string{"table", "column", "index"} {
+			p.AddOption(name, name).Long(name)
+		}
+		p.SetDefaults(func(c *Context) {})
+	})
+	download := parser.AddParser("download", "download help")
+	download.SetDefaults(func(c *Context) {})
+
+	// 构建子命令时，其他命令的解析不受影响
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if result := parser.ParseArgs([]string{"schema", "--table", "users"}); result.err != nil {
+				t.Error(result.err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if result := parser.ParseArgs([]string{"download", "--mode", "fast"}); result.err != nil {
+				t.Error(result.err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -1,32 +1,54 @@
 package goargs
 
 import (
-	"errors"
 	"fmt"
+	"reflect"
 	"strings"
+	"time"
 )
 
+// 将字符串参数值解析为指定类型
+type decodeFunc func(s string) (interface{}, error)
+
 type Option struct {
 	shortV    rune        // 简写选项，比如 -m
 	longV     string      // 完整选项，比如 --mode
+	aliases   []string    // long的别名，比如 --out
+	name      string      // AddOption时的名称，未设置Short和Long时作为默认的long
 	dest      string      // 关键字，用户获取Option的数据
 	requiredV bool        // 标记当前参数是否是必选
 	help      string      // 帮助信息
 	defValue  interface{} // 参数的默认值
-	value     interface{} // 参数的值
-	stored    bool        // 标记是否已经处理过option
 	setBool   bool        // 标记是否设置了BoolV
-	boolV     bool        // Bool的默认值
+	boolV     bool        // 参数出现时的值，未出现时为相反值
+	envV      string      // 环境变量名，比如 APP_MODE
+	isConfig  bool        // 标记Option的值为配置文件路径
+	multi     bool        // 标记Option可以重复设置，值为[]string
+	target    interface{} // 绑定的变量，解析完成后回写
+	decoder   decodeFunc  // 字符串参数值的解析函数，用于带类型的Option
+	action    Action      // 参数出现时执行的动作，为空时保存参数值
+	local     bool        // 标记Option只在所属的命令生效，不被子命令继承
+	hidden    bool        // 不在帮助信息中显示
+	deprecV   string      // 弃用说明，不为空时表示Option已弃用
+	choices   []string    // 可选的参数值，为空时不限制
+	secret    bool        // 敏感信息，帮助信息以及 --print-config 中不显示参数值
+	metavarV  string      // 帮助信息中参数值的占位符，比如 FILE
+	isArg     bool        // 标记为位置参数
+	dropped   bool        // 在冲突中失去过名称，不再使用dest作为默认的long
+	orderV    int         // 在帮助信息中的顺序
+	group     *Group      // 所属的分组，为空时不分组
 	father    *Parser     // Option的关联解析器， 目的是回写Short和Long Option
+
+	valueType reflect.Type // 参数值的类型，用于帮助信息
 }
 
 func newOption(dest string, help string, father *Parser) *Option {
 	self := &Option{
 		//longV: dest,  // 不能设定，只有当short和long都没有设定时，longV才被设置为dest
+		name:      dest,
 		dest:      dest,
 		help:      help,
 		requiredV: false,
-		stored:    false,
 		setBool:   false,
 		father:    father,
 	}
@@ -36,23 +58,126 @@ func newOption(dest string, help string, father *Parser) *Option {
 func (self *Option) Short(s rune) *Option {
 	self.shortV = s
 	// 将自身挂载到所属Parser的ShortOpts下面，用于short查找
-	self.father.ShortOpts[s] = self
+	lookup := func(p *Parser) *Option { return p.ShortOpts[s] }
+	drop := func(old *Option) { old.shortV, old.dropped = 0, true }
+	if self.father.checkConflict(self, fmt.Sprintf("-%c", s), lookup, drop) {
+		self.father.ShortOpts[s] = self
+	}
+	self.father.changed()
 	return self
 }
 
 func (self *Option) Long(l string) *Option {
 	self.longV = l
 	// 将自身挂载到所属Parser的LongOpts下面，用于long查找
-	self.father.LongOpts[l] = self
+	lookup := func(p *Parser) *Option { return p.LongOpts[l] }
+	drop := func(old *Option) { old.dropLong(l) }
+	if self.father.checkConflict(self, "--"+l, lookup, drop) {
+		self.father.LongOpts[l] = self
+	}
+	self.father.changed()
+	return self
+}
+
+// long的别名，与long一样可以用于命令行
+func (self *Option) Aliases(names ...string) *Option {
+	for _, l := range names {
+		l := l
+		lookup := func(p *Parser) *Option { return p.LongOpts[l] }
+		drop := func(old *Option) { old.dropLong(l) }
+		if self.father.checkConflict(self, "--"+l, lookup, drop) {
+			self.aliases = append(self.aliases, l)
+			self.father.LongOpts[l] = self
+		}
+	}
+	self.father.changed()
 	return self
 }
 
+// 失去long或者别名
+func (self *Option) dropLong(l string) {
+	self.dropped = true
+	if self.longV == l {
+		self.longV = ""
+		return
+	}
+	for i, v := range self.aliases {
+		if v == l {
+			self.aliases = append(self.aliases[:i:i], self.aliases[i+1:]...)
+			return
+		}
+	}
+}
+
 func (self *Option) Bool(b bool) *Option {
 	self.boolV = b
 	self.setBool = true
 	return self
 }
 
+// 设置dest，多个Option可以共享同一个dest
+func (self *Option) Dest(d string) *Option {
+	self.dest = d
+	self.father.changed()
+	return self
+}
+
+// Option被所有子命令继承，默认行为
+func (self *Option) Persistent() *Option {
+	self.local = false
+	self.father.changed()
+	return self
+}
+
+// Option只在所属的命令生效，不被子命令继承
+func (self *Option) Local() *Option {
+	self.local = true
+	self.father.changed()
+	return self
+}
+
+// 隐藏Option，依然可以使用，只在 --help-all 中显示
+func (self *Option) Hidden() *Option {
+	self.hidden = true
+	return self
+}
+
+// 标记Option已弃用，依然可以使用，使用时输出警告，默认不在帮助信息中显示
+// 配合Dest可以转发到新的Option，比如 AddOption("out", "output file").Long("out").Dest("output").Deprecated("use --output instead")
+func (self *Option) Deprecated(msg string) *Option {
+	self.deprecV = msg
+	return self
+}
+
+// 帮助信息中是否隐藏
+func (self *Option) isHidden() bool {
+	return self.hidden || self.deprecV != ""
+}
+
+// 设置在帮助信息中的顺序，数值小的在前，相同时按HelpOrder排列
+func (self *Option) Order(n int) *Option {
+	self.orderV = n
+	return self
+}
+
+// 限制参数值只能是其中之一，可重复的Option检查每一个值
+func (self *Option) Choices(values ...string) *Option {
+	self.choices = values
+	return self
+}
+
+// 设置帮助信息中参数值的占位符，比如 Metavar("FILE") 显示为 --out FILE
+func (self *Option) Metavar(name string) *Option {
+	self.metavarV = name
+	return self
+}
+
+// 标记为敏感信息，比如密码，帮助信息以及 --print-config 中不显示参数值
+func (self *Option) Secret() *Option {
+	self.secret = true
+	return self
+}
+
 func (self *Option) Required() *Option {
 	self.requiredV = true
 	return self
@@ -63,28 +188,57 @@ func (self *Option) Default(v interface{}) *Option {
 	return self
 }
 
-func (self *Option) getString() string {
-	if self.value == nil {
-		if self.defValue != nil {
-			return self.defValue.(string)
-		}
-		return ""
-	} else {
-		return self.value.(string)
-	}
+// 命令行未设置时，从环境变量中读取
+func (self *Option) Env(name string) *Option {
+	self.envV = name
+	return self
 }
 
-func (self *Option) getBool() bool {
-	if self.setBool {
-		return self.boolV
+// 标记Option的值为配置文件路径，比如 --config
+func (self *Option) ConfigPath() *Option {
+	self.isConfig = true
+	return self
+}
+
+// 解析完成后，将参数值写入绑定的变量
+func (self *Option) StringVar(p *string) *Option {
+	self.target = p
+	return self
+}
+
+func (self *Option) IntVar(p *int) *Option {
+	self.target = p
+	return self
+}
+
+// 未设置Bool时，默认为出现即为true
+func (self *Option) BoolVar(p *bool) *Option {
+	if !self.setBool {
+		self.Bool(true)
 	}
-	return self.value.(bool)
+	self.target = p
+	return self
+}
+
+func (self *Option) DurationVar(p *time.Duration) *Option {
+	self.target = p
+	return self
+}
+
+// 参数可以重复设置，比如 --tag a --tag b,c，结果为 [a b c]
+func (self *Option) StringSliceVar(p *[]string) *Option {
+	self.multi = true
+	self.target = p
+	return self
 }
 
 func (self *Option) getOptString() string {
+	if self.isArg {
+		return strings.Trim(self.argString(), "[]")
+	}
 	out := []string{}
 	if self.shortV == 0 && self.longV == "" {
-		out = append(out, fmt.Sprintf("--%s", self.dest))
+		out = append(out, fmt.Sprintf("--%s", self.name))
 	}
 	if self.shortV != 0 {
 		out = append(out, fmt.Sprintf("-%c", self.shortV))
@@ -95,44 +249,88 @@ func (self *Option) getOptString() string {
 	return strings.Join(out, "/")
 }
 
-func (self *Option) valid() error {
-	if self.stored && self.value == nil && self.defValue == nil {
-		return errors.New(fmt.Sprintf("Missing required option: '%s'", self.getOptString()))
+// 检查参数值是否在Choices中
+func (self *Option) checkChoice(s string) error {
+	if len(self.choices) == 0 {
+		return nil
+	}
+	for _, v := range self.choices {
+		if v == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid choice '%s' for option: '%s' (choose from %s)", s, self.getOptString(), strings.Join(self.choices, ", "))
+}
+
+// 参数是否需要参数值，Bool类型的Option不需要
+func (self *Option) needValue() bool {
+	if self.setBool {
+		return false
 	}
+	if self.action != nil {
+		return self.action.NeedValue()
+	}
+	return true
+}
 
-	if !self.stored && self.defValue == nil {
-		return errors.New(fmt.Sprintf("Missing required option: '%s'", self.getOptString()))
+// 不需要参数值时，参数出现时的值
+func (self *Option) flagValue() interface{} {
+	if self.setBool {
+		return self.boolV
 	}
 	return nil
 }
 
-// 检查Value是否合法，并赋值给Option
-func (self *Option) parse(v interface{}) (err error) {
-	self.stored = true
-	self.value = v
-
-	err = self.valid()
-	return
+// 处理命令行中的参数，有Action时执行Action，否则保存参数值
+func (self *Option) apply(cx *Context, v interface{}) error {
+	if self.deprecV != "" {
+		if err := self.father.deprecate("Option '%s' is deprecated: %s", self.getOptString(), self.deprecV); err != nil {
+			return err
+		}
+	}
+	if self.action == nil {
+		return cx.value(self).parse(v)
+	}
+	s := ""
+	if v != nil {
+		s = fmt.Sprint(v)
+	}
+	// 回调保存原始的参数值，Bool类型的Option保存bool
+	if cb, ok := self.action.(*callbackAction); ok {
+		if err := cx.value(self).parse(v); err != nil {
+			return err
+		}
+		return cb.fn(cx, s)
+	}
+	return self.action.Run(cx, self.dest, s)
 }
 
 // 预处理Option
 func (self *Option) pre() {
-	// 当时用户未显示设置Short和Long时，Long默认和Dest一样
-	if self.longV == "" && self.shortV == 0 {
-		self.Long(self.dest)
+	// 当时用户未显示设置Short和Long时，Long默认为AddOption时的名称，Dest转发到其他Option时不变
+	// 只设置一次；在冲突中失去名称的Option不再取回该名称
+	if self.longV != "" || self.shortV != 0 || self.dropped || self.isArg {
+		return
 	}
+	// ConflictResolve时，默认的long让给显式设置该long的Option
+	if old, ok := self.father.LongOpts[self.name]; ok && old != self && self.father.conflict == ConflictResolve {
+		self.dropped = true
+		return
+	}
+	self.Long(self.name)
 }
 
-// 后处理Option
-func (self *Option) post() (err error) {
-	// 检查所有必选参数是否已经设置
-	if self.requiredV && self.defValue == nil && self.value == nil {
-		err = errors.New(fmt.Sprintf("Missing required option: '%s'", self.getOptString()))
-		return
+// 命令行中可以使用的名称，比如 -f、--file以及别名
+func (self *Option) names() []string {
+	out := []string{}
+	if self.shortV != 0 {
+		out = append(out, fmt.Sprintf("-%c", self.shortV))
+	}
+	if self.longV != "" {
+		out = append(out, "--"+self.longV)
 	}
-	// 检查Bool值是否已经设置
-	if !self.stored {
-		self.boolV = !self.boolV
+	for _, v := range self.aliases {
+		out = append(out, "--"+v)
 	}
-	return
+	return out
 }
@@ -1,6 +1,11 @@
 package goargs
 
-import "testing"
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
 
 func Test_Option_Basic(t *testing.T) {
 	var err error
@@ -11,7 +16,8 @@ func Test_Option_Basic(t *testing.T) {
 			LongOpts:  map[string]*Option{},
 		}
 		arg := newOption("mode", "help mode", parser)
-		if err = arg.parse("test"); err != nil {
+		value := &optionValue{option: arg}
+		if err = value.parse("test"); err != nil {
 			t.Error(err)
 		}
 
@@ -40,11 +46,11 @@ func Test_Option_Basic(t *testing.T) {
 			t.Error()
 		}
 
-		if !arg.stored {
+		if !value.stored {
 			t.Error()
 		}
 
-		if v := arg.getString(); "test" != v {
+		if v := value.getString(); "test" != v {
 			t.Error()
 		}
 	}
@@ -55,7 +61,8 @@ func Test_Option_Basic(t *testing.T) {
 			LongOpts:  map[string]*Option{},
 		}
 		arg := newOption("mode", "help mode", parser).Long("modeopt")
-		if err = arg.parse("test"); err != nil {
+		value := &optionValue{option: arg}
+		if err = value.parse("test"); err != nil {
 			t.Error(err)
 		}
 
@@ -84,11 +91,11 @@ func Test_Option_Basic(t *testing.T) {
 			t.Error()
 		}
 
-		if !arg.stored {
+		if !value.stored {
 			t.Error()
 		}
 
-		if v := arg.getString(); "test" != v {
+		if v := value.getString(); "test" != v {
 			t.Error()
 		}
 	}
@@ -99,7 +106,8 @@ func Test_Option_Basic(t *testing.T) {
 			LongOpts:  map[string]*Option{},
 		}
 		arg := newOption("mode", "help mode", parser).Short('x')
-		if err = arg.parse("test"); err != nil {
+		value := &optionValue{option: arg}
+		if err = value.parse("test"); err != nil {
 			t.Error(err)
 		}
 
@@ -128,11 +136,11 @@ func Test_Option_Basic(t *testing.T) {
 			t.Error()
 		}
 
-		if !arg.stored {
+		if !value.stored {
 			t.Error()
 		}
 
-		if v := arg.getString(); "test" != v {
+		if v := value.getString(); "test" != v {
 			t.Error()
 		}
 	}
@@ -143,7 +151,8 @@ func Test_Option_Basic(t *testing.T) {
 			LongOpts:  map[string]*Option{},
 		}
 		arg := newOption("mode", "help mode", parser).Required()
-		if err = arg.parse("test"); err != nil {
+		value := &optionValue{option: arg}
+		if err = value.parse("test"); err != nil {
 			t.Error(err)
 		}
 
@@ -172,11 +181,11 @@ func Test_Option_Basic(t *testing.T) {
 			t.Error()
 		}
 
-		if !arg.stored {
+		if !value.stored {
 			t.Error()
 		}
 
-		if v := arg.getString(); "test" != v {
+		if v := value.getString(); "test" != v {
 			t.Error()
 		}
 	}
@@ -187,12 +196,13 @@ func Test_Option_Basic(t *testing.T) {
 			LongOpts:  map[string]*Option{},
 		}
 		arg := newOption("mode", "help mode", parser).Default("admin")
+		value := &optionValue{option: arg}
 
-		if d := arg.getString(); "admin" != d {
+		if d := value.getString(); "admin" != d {
 			t.Error()
 		}
 
-		if err = arg.parse("test"); err != nil {
+		if err = value.parse("test"); err != nil {
 			t.Error(err)
 		}
 
@@ -221,11 +231,11 @@ func Test_Option_Basic(t *testing.T) {
 			t.Error()
 		}
 
-		if !arg.stored {
+		if !value.stored {
 			t.Error()
 		}
 
-		if v := arg.getString(); "test" != v {
+		if v := value.getString(); "test" != v {
 			t.Error()
 		}
 	}
@@ -236,12 +246,14 @@ func Test_Option_Basic(t *testing.T) {
 			LongOpts:  map[string]*Option{},
 		}
 		arg := newOption("mode", "help mode", parser).Bool(true)
+		value := &optionValue{option: arg}
 
-		if d := arg.getBool(); true != d {
+		// 未出现时为相反值
+		if d := value.getBool(); false != d {
 			t.Error()
 		}
 
-		if err = arg.parse("test"); err != nil {
+		if err = value.parse("test"); err != nil {
 			t.Error(err)
 		}
 
@@ -270,11 +282,11 @@ func Test_Option_Basic(t *testing.T) {
 			t.Error()
 		}
 
-		if !arg.stored {
+		if !value.stored {
 			t.Error()
 		}
 
-		if v := arg.getString(); "test" != v {
+		if v := value.getString(); "test" != v {
 			t.Error()
 		}
 	}
@@ -286,12 +298,13 @@ func Test_Option_Basic(t *testing.T) {
 			LongOpts:  map[string]*Option{},
 		}
 		arg := newOption("mode", "help mode", parser).Short('m').Long("modex").Required().Default("admin")
+		value := &optionValue{option: arg}
 
-		if d := arg.getString(); "admin" != d {
+		if d := value.getString(); "admin" != d {
 			t.Error()
 		}
 
-		if err = arg.parse("test"); err != nil {
+		if err = value.parse("test"); err != nil {
 			t.Error(err)
 		}
 
@@ -320,11 +333,11 @@ func Test_Option_Basic(t *testing.T) {
 			t.Error()
 		}
 
-		if !arg.stored {
+		if !value.stored {
 			t.Error()
 		}
 
-		if v := arg.getString(); "test" != v {
+		if v := value.getString(); "test" != v {
 			t.Error()
 		}
 	}
@@ -340,6 +353,7 @@ func Test_Option_Required(t *testing.T) {
 	}
 
 	arg = newOption("mode", "help mode", parser).Required()
+	value := &optionValue{option: arg}
 
 	// check
 	if "mode" != arg.dest {
@@ -362,11 +376,11 @@ func Test_Option_Required(t *testing.T) {
 		t.Error()
 	}
 
-	if true == arg.stored {
+	if true == value.stored {
 		t.Error()
 	}
 
-	err = arg.valid()
+	err = value.valid()
 
 	if err == nil {
 		t.Error()
@@ -378,17 +392,18 @@ func Test_Option_Required(t *testing.T) {
 
 	arg.Default("test")
 
-	err = arg.valid()
+	err = value.valid()
 
 	if err != nil {
 		t.Error()
 	}
 
 	arg = newOption("mode", "help mode", parser).Required().Short('m')
+	value = &optionValue{option: arg}
 	if 'm' != arg.shortV {
 		t.Error()
 	}
-	err = arg.valid()
+	err = value.valid()
 	if err == nil {
 		t.Error()
 	}
@@ -396,10 +411,11 @@ func Test_Option_Required(t *testing.T) {
 		t.Error(err)
 	}
 	arg = newOption("mode", "help mode", parser).Required().Short('m').Long("mymode")
+	value = &optionValue{option: arg}
 	if 'm' != arg.shortV {
 		t.Error()
 	}
-	err = arg.valid()
+	err = value.valid()
 	if err == nil {
 		t.Error()
 	}
@@ -407,3 +423,98 @@ func Test_Option_Required(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func Test_Option_Var(t *testing.T) {
+	var mode string
+	var count int
+	var force bool
+	var timeout time.Duration
+	var tags []string
+
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Short('m').Default("test").StringVar(&mode)
+	parser.AddOption("count", "count").Short('n').Default(3).IntVar(&count)
+	parser.AddOption("force", "force do something").Short('f').BoolVar(&force)
+	parser.AddOption("timeout", "timeout").Env("GOARGS_TEST_TIMEOUT").DurationVar(&timeout)
+	parser.AddOption("tag", "tags").Short('t').Long("tag").StringSliceVar(&tags)
+
+	os.Setenv("GOARGS_TEST_TIMEOUT", "3s")
+	defer os.Unsetenv("GOARGS_TEST_TIMEOUT")
+
+	result := parser.ParseArgs([]string{"-f", "-t", "a", "--tag=b,c"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+
+	assertEqual(t, "test", mode)
+	assertEqualInt(t, 3, count)
+	if !force {
+		t.Error("Expect force is true")
+	}
+	if timeout != 3*time.Second {
+		t.Error(timeout)
+	}
+	assertEqual(t, "a,b,c", strings.Join(tags, ","))
+
+	// Context的方法依然可用
+	if v, err := result.cx.GetInt("count"); err != nil || v != 3 {
+		t.Error(v, err)
+	}
+	if v, err := result.cx.GetStringSlice("tag"); err != nil || len(v) != 3 {
+		t.Error(v, err)
+	}
+	if v, err := result.cx.GetDuration("timeout"); err != nil || v != 3*time.Second {
+		t.Error(v, err)
+	}
+}
+
+func Test_Option_VarInvalid(t *testing.T) {
+	var count int
+
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("count", "count").Short('n').IntVar(&count)
+
+	result := parser.ParseArgs([]string{"-n", "x"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Invalid int value 'x' for option: '-n'", result.err.Error())
+}
+
+func Test_Option_Choices(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Short('m').Choices("fast", "slow")
+	parser.AddOption("tag", "tags").Long("tag").StringSliceVar(new([]string)).Choices("a", "b")
+
+	result := parser.ParseArgs([]string{"-m", "fast", "--tag", "a,b"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+
+	result = parser.ParseArgs([]string{"-m", "debug"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Invalid choice 'debug' for option: '-m' (choose from fast, slow)", result.err.Error())
+
+	result = parser.ParseArgs([]string{"-m", "fast", "--tag", "a,c"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Invalid choice 'c' for option: '--tag' (choose from a, b)", result.err.Error())
+}
+
+func Test_Option_Secret(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("password", "password").Long("password").Default("123456").Secret()
+
+	result := parser.ParseArgs([]string{})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "password = ****** (default)\n", result.cx.explain())
+
+	// 解析得到的参数值不受影响
+	password, _ := result.cx.GetString("password")
+	assertEqual(t, "123456", password)
+}
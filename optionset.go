@@ -0,0 +1,103 @@
+package goargs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 可复用的Option集合，比如多个子命令都需要的 --region、--profile
+// 挂载时复制Option的定义，各Parser之间不共享解析状态
+type OptionSet struct {
+	parser *Parser // 用于定义Option，不参与解析
+}
+
+func NewOptionSet() *OptionSet {
+	return &OptionSet{
+		parser: ArgumentParser("", ""),
+	}
+}
+
+func (self *OptionSet) AddOption(dest string, help string) *Option {
+	return self.parser.AddOption(dest, help)
+}
+
+// 挂载时，在目标Parser中添加同名的分组
+func (self *OptionSet) AddGroup(name string, help string) *Group {
+	return self.parser.AddGroup(name, help)
+}
+
+// 将OptionSet挂载到当前Parser，与已有的Option冲突时返回错误，不挂载任何Option
+func (self *Parser) AddOptionSet(set *OptionSet) error {
+	names := []string{}
+	for k := range set.parser.Opts {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	conflicts := []string{}
+	for _, k := range names {
+		conflicts = append(conflicts, self.optionConflicts(k, set.parser.Opts[k])...)
+	}
+	if len(conflicts) > 0 {
+		return errors.New(strings.Join(conflicts, "; "))
+	}
+
+	// 按OptionSet中的声明顺序挂载
+	for _, k := range set.parser.optNames {
+		option := *set.parser.Opts[k]
+		option.father = self
+		if option.group != nil {
+			option.group = self.AddGroup(option.group.Name, option.group.Help)
+		}
+		self.Opts[k] = &option
+		self.optNames = appendName(self.optNames, k)
+		// 与定义Option时一样注册名称，检查冲突
+		short, long, aliases := option.shortV, option.longV, option.aliases
+		option.shortV, option.longV, option.aliases = 0, "", nil
+		if short != 0 {
+			option.Short(short)
+		}
+		if long != "" {
+			option.Long(long)
+		}
+		option.Aliases(aliases...)
+	}
+	self.changed()
+	return nil
+}
+
+// 检查Option与当前Parser已有的Option是否冲突
+func (self *Parser) optionConflicts(name string, option *Option) []string {
+	out := []string{}
+	if v, ok := self.Opts[name]; ok {
+		out = append(out, fmt.Sprintf("Option '%s' conflicts with the option '%s' in %s", name, v.dest, self.Title))
+	}
+	if v, ok := self.ShortOpts[option.shortV]; ok && option.shortV != 0 {
+		out = append(out, fmt.Sprintf("Flag '-%c' of '%s' conflicts with the option '%s' in %s", option.shortV, option.dest, v.dest, self.Title))
+	}
+	long := option.longV
+	if long == "" && option.shortV == 0 {
+		long = option.name
+	}
+	for _, l := range append([]string{long}, option.aliases...) {
+		if v, ok := self.findLongOption(l); ok {
+			out = append(out, fmt.Sprintf("Flag '--%s' of '%s' conflicts with the option '%s' in %s", l, option.dest, v.dest, self.Title))
+		}
+	}
+	return out
+}
+
+// 查找Long选项，包括未设置Short和Long，Long默认为dest的Option
+func (self *Parser) findLongOption(long string) (*Option, bool) {
+	if v, ok := self.LongOpts[long]; ok {
+		return v, true
+	}
+	for _, v := range self.Opts {
+		if v.longV == "" && v.shortV == 0 && v.name == long {
+			return v, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,107 @@
+package goargs
+
+import (
+	"testing"
+)
+
+func genCommonOptions() *OptionSet {
+	set := NewOptionSet()
+	set.AddOption("region", "region").Long("region").Default("cn")
+	set.AddOption("profile", "profile").Short('p').Long("profile")
+	set.AddOption("output", "output format")
+	return set
+}
+
+func TestOptionSet(t *testing.T) {
+	set := genCommonOptions()
+
+	parser := ArgumentParser("app", "help")
+	upload := parser.AddParser("upload", "upload help")
+	download := parser.AddParser("download", "download help")
+	if err := upload.AddOptionSet(set); err != nil {
+		t.Fatal(err)
+	}
+	if err := download.AddOptionSet(set); err != nil {
+		t.Fatal(err)
+	}
+
+	if upload.Opts["region"] == download.Opts["region"] {
+		t.Error("Expect the options are copied")
+	}
+
+	result := parser.ParseArgs([]string{"upload", "-p", "dev", "--output", "json"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	profile, _ := result.cx.GetString("profile")
+	assertEqual(t, "dev", profile)
+	region, _ := result.cx.GetString("region")
+	assertEqual(t, "cn", region)
+	output, _ := result.cx.GetString("output")
+	assertEqual(t, "json", output)
+
+	// 不共享解析状态
+	result = parser.ParseArgs([]string{"download"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	if result.cx.IsSet("profile") {
+		t.Error("Expect the profile of download is not set")
+	}
+}
+
+func TestOptionSetConflict(t *testing.T) {
+	set := genCommonOptions()
+
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("path", "path").Short('p')
+	parser.AddOption("output", "output file")
+
+	err := parser.AddOptionSet(set)
+	if err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Option 'output' conflicts with the option 'output' in root; "+
+		"Flag '--output' of 'output' conflicts with the option 'output' in root; "+
+		"Flag '-p' of 'profile' conflicts with the option 'path' in root", err.Error())
+
+	// 冲突时不挂载任何Option
+	if _, ok := parser.Opts["region"]; ok {
+		t.Error("Expect the region is not attached")
+	}
+}
+
+func TestOptionSetShadow(t *testing.T) {
+	set := genCommonOptions()
+
+	// 挂载的Option覆盖父命令的Option
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("path", "path").Short('p')
+	upload := parser.AddParser("upload", "upload help")
+	if err := upload.AddOptionSet(set); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting option -p: 'profile' in upload shadows 'path' in root", err.Error())
+	}
+	if result := parser.ParseArgs([]string{"upload", "-p", "dev"}); result.err == nil {
+		t.Fatal()
+	}
+
+	// 允许覆盖时使用挂载的Option
+	parser = ArgumentParser("app", "help")
+	parser.SetConflictHandler(ConflictShadow)
+	parser.AddOption("path", "path").Short('p')
+	upload = parser.AddParser("upload", "upload help")
+	if err := upload.AddOptionSet(set); err != nil {
+		t.Fatal(err)
+	}
+	result := parser.ParseArgs([]string{"upload", "-p", "dev"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	profile, _ := result.cx.GetString("profile")
+	assertEqual(t, "dev", profile)
+}
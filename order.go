@@ -0,0 +1,83 @@
+package goargs
+
+import (
+	"sort"
+)
+
+// 帮助信息中子命令和Option的排列方式
+type HelpOrder int
+
+const (
+	// 默认，按声明顺序
+	OrderDeclaration HelpOrder = iota
+	// 按名称的字母顺序
+	OrderAlphabetical
+)
+
+// 设置帮助信息的排列方式，Order设置的顺序优先
+func (self *Parser) SetHelpOrder(o HelpOrder) {
+	self.root().helpOrder = o
+}
+
+// 设置子命令在帮助信息中的顺序，数值小的在前，相同时按HelpOrder排列
+func (self *Parser) Order(n int) *Parser {
+	self.order = n
+	return self
+}
+
+// 按声明顺序记录名称，同名时保留原来的位置
+func appendName(names []string, name string) []string {
+	for _, v := range names {
+		if v == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
+
+// 按帮助信息的顺序返回当前Parser的Option
+func (self *Parser) orderedOptions() []*Option {
+	opts := []*Option{}
+	for _, k := range self.optNames {
+		if v, ok := self.Opts[k]; ok {
+			opts = append(opts, v)
+		}
+	}
+	alphabetical := self.root().helpOrder == OrderAlphabetical
+	sort.SliceStable(opts, func(i, j int) bool {
+		if opts[i].orderV != opts[j].orderV {
+			return opts[i].orderV < opts[j].orderV
+		}
+		return alphabetical && opts[i].sortName() < opts[j].sortName()
+	})
+	return opts
+}
+
+// 按帮助信息的顺序返回子命令
+func (self *Parser) orderedSubs() []*Parser {
+	subs := []*Parser{}
+	for _, k := range self.subNames {
+		if v, ok := self.Subs[k]; ok {
+			subs = append(subs, v)
+		}
+	}
+	alphabetical := self.root().helpOrder == OrderAlphabetical
+	sort.SliceStable(subs, func(i, j int) bool {
+		if subs[i].order != subs[j].order {
+			return subs[i].order < subs[j].order
+		}
+		return alphabetical && subs[i].Name < subs[j].Name
+	})
+	return subs
+}
+
+// 字母排序使用的名称，优先使用long
+func (self *Option) sortName() string {
+	if self.longV != "" {
+		return self.longV
+	}
+	if self.shortV != 0 {
+		return string(self.shortV)
+	}
+	return self.name
+}
@@ -0,0 +1,70 @@
+package goargs
+
+import (
+	"testing"
+)
+
+func TestDeclarationOrder(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode")
+	parser.AddOption("config", "config file").Short('c').Long("config")
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	parser.AddParser("upload", "upload files")
+	parser.AddParser("download", "download files")
+	parser.AddParser("check", "check files")
+	assertEqual(t, ""+
+		"  -m, --mode   testing mode\n"+
+		"  -c, --config config file\n"+
+		"  -f           force do something\n", parser.OptionDetailText())
+	assertEqual(t, "upload   upload files\ndownload download files\ncheck    check files\n", parser.SubCommandText())
+	assertEqual(t, "[-m/--mode MODE] [-c/--config CONFIG] [-f] <command>", parser.OptionText())
+}
+
+func TestCustomOrder(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode")
+	parser.AddOption("config", "config file").Short('c').Long("config")
+	parser.AddOption("force", "force do something").Short('f').Bool(true).Order(-1)
+	parser.AddParser("upload", "upload files").Order(1)
+	parser.AddParser("download", "download files")
+	parser.AddParser("check", "check files").Order(-1)
+	assertEqual(t, ""+
+		"  -f           force do something\n"+
+		"  -m, --mode   testing mode\n"+
+		"  -c, --config config file\n", parser.OptionDetailText())
+	assertEqual(t, "check    check files\ndownload download files\nupload   upload files\n", parser.SubCommandText())
+}
+
+func TestAlphabeticalOrder(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode")
+	parser.AddOption("config", "config file").Short('c').Long("config")
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	parser.AddParser("upload", "upload files")
+	parser.AddParser("download", "download files")
+	parser.AddParser("check", "check files")
+	parser.SetHelpOrder(OrderAlphabetical)
+	assertEqual(t, ""+
+		"  -c, --config config file\n"+
+		"  -f           force do something\n"+
+		"  -m, --mode   testing mode\n", parser.OptionDetailText())
+	assertEqual(t, "check    check files\ndownload download files\nupload   upload files\n", parser.SubCommandText())
+
+	// Order优先
+	parser.Subs["upload"].Order(-1)
+	assertEqual(t, "upload   upload files\ncheck    check files\ndownload download files\n", parser.SubCommandText())
+}
+
+func TestOptionSetOrder(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("verbose", "verbose output").Long("verbose")
+	if err := parser.AddOptionSet(genCommonOptions()); err != nil {
+		t.Fatal(err)
+	}
+	parser.Compile()
+	assertEqual(t, ""+
+		"      --verbose verbose output\n"+
+		"      --region  region\n"+
+		"  -p, --profile profile\n"+
+		"      --output  output format\n", parser.OptionDetailText())
+}
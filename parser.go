@@ -4,19 +4,25 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"html/template"
+	"io"
 	"os"
-	"sort"
 	"strings"
+	"sync"
 )
 
 var (
 	ERR_Usage    = errors.New("Usage")
 	ERR_NotFound = errors.New("NotFound")
+
+	// 内置的 --print-config，解析完成后输出所有参数的值以及来源
+	errPrintConfig = errors.New("PrintConfig")
 )
 
 type Handler func(c *Context)
 
+// 用于测试中替换
+var osExit = os.Exit
+
 type Parser struct {
 	Name        string
 	Title       string
@@ -24,10 +30,53 @@ type Parser struct {
 	Super       *Parser
 	Root        *Parser
 	Subs        map[string]*Parser // method - parser
-	Opts        map[string]*Option // dest - option
+	Opts        map[string]*Option // name - option，name默认即为dest
 	ShortOpts   map[rune]*Option   // short - option
 	LongOpts    map[string]*Option // long - option
 	HandlerFunc Handler
+
+	configFile   string          // 默认的配置文件路径
+	configStrict bool            // 配置文件中出现未知的key时是否报错
+	conflict     ConflictHandler // Option重复时的处理方式
+	defErrs      []Finding       // 定义时发现的错误，ParseArgs时返回
+	errMu        sync.Mutex      // 保护defErrs，延迟构建可能与其他命令的解析并发
+	allowAbbrev  bool            // 是否允许long的缩写
+	gen          int64           // 定义的版本，定义改变时增加，通过atomic访问
+	table        *lookupTable    // 编译后的查找表
+	mu           sync.RWMutex    // 保护查找表的编译，解析状态保存在Context中
+	builder      func(p *Parser) // 延迟构建子命令
+	once         sync.Once       // 保证builder只执行一次
+	built        bool            // 延迟构建已完成，之前遍历命令树时跳过
+
+	aliases   []string           // 子命令的别名，比如 rm
+	aliasSubs map[string]*Parser // alias - parser
+	hidden    bool               // 不在帮助信息中显示
+
+	deprecated       string    // 弃用说明，不为空时表示子命令已弃用
+	deprecatedStrict bool      // 使用弃用的子命令或Option时是否报错
+	errOutput        io.Writer // 警告信息的输出，默认为os.Stderr
+
+	optNames  []string  // 按声明顺序记录的Option名称
+	subNames  []string  // 按声明顺序记录的子命令名称
+	order     int       // 子命令在帮助信息中的顺序
+	helpOrder HelpOrder // 帮助信息的排列方式
+	groups    []*Group  // Option分组
+	category  string    // 子命令的分类
+
+	formatter HelpFormatter // 帮助信息的格式，为空时使用父命令的设置
+
+	shadowErrs []Finding // 子命令覆盖父命令Option的错误，为nil时未检查
+	shadowGen  int64     // 检查覆盖时的定义版本
+
+	args []*Option // 位置参数，按声明顺序
+
+	short    string    // 子命令列表中的简短描述，为空时使用Help
+	long     string    // 帮助信息开头的详细描述，为空时使用Help
+	epilog   string    // 帮助信息结尾的说明
+	examples []Example // 用法示例
+
+	version     *VersionInfo // 版本信息，为空时使用父命令的设置
+	versionTmpl string       // 版本信息的模板，为空时使用父命令的设置
 }
 
 type Result struct {
@@ -35,6 +84,7 @@ type Result struct {
 	err         error
 	cx          *Context
 	HandlerFunc Handler
+	parser      *Parser // 匹配的命令
 }
 
 func ArgumentParser(n string, h string) *Parser {
@@ -66,55 +116,162 @@ func (self *Parser) AddParser(cmd string, help string) *Parser {
 		ShortOpts:   map[rune]*Option{},
 		LongOpts:    map[string]*Option{},
 		HandlerFunc: nil,
+		conflict:    self.conflict,
+	}
+	// 与已有的子命令或别名冲突时记录错误，不注册该子命令
+	if old, ok := self.lookupSub(cmd); ok {
+		p.defError("Conflicting command '%s': '%s' conflicts with '%s' in %s", cmd, cmd, old.Name, self.Title)
+		return p
 	}
 	self.Subs[cmd] = p
+	self.subNames = appendName(self.subNames, cmd)
+	self.changed()
 	return p
 }
 
 func (self *Parser) AddOption(dest string, help string) *Option {
 	arg := newOption(dest, help, self)
+	if old, ok := self.Opts[dest]; ok && self.conflict != ConflictResolve {
+		self.defError("Conflicting option '%s': '%s' conflicts with '%s' in %s", dest, dest, old.dest, self.Title)
+		return arg
+	}
 	self.Opts[dest] = arg
+	self.optNames = appendName(self.optNames, dest)
+	self.changed()
 	return arg
 }
 
+// 子命令的别名，比如 AddParser("remove", ...).Aliases("rm", "del")
+func (self *Parser) Aliases(names ...string) *Parser {
+	super := self.Super
+	if super == nil {
+		return self
+	}
+	if super.aliasSubs == nil {
+		super.aliasSubs = map[string]*Parser{}
+	}
+	for _, name := range names {
+		if old, ok := super.lookupSub(name); ok {
+			self.defError("Conflicting command '%s': '%s' conflicts with '%s' in %s", name, self.Name, old.Name, super.Title)
+			continue
+		}
+		self.aliases = append(self.aliases, name)
+		super.aliasSubs[name] = self
+	}
+	return self
+}
+
+// 隐藏子命令，依然可以使用，只在 --help-all 中显示
+func (self *Parser) Hidden() *Parser {
+	self.hidden = true
+	return self
+}
+
+// 标记子命令已弃用，依然可以使用，使用时输出警告，比如 Deprecated("use upload instead")
+// 弃用的子命令默认不在帮助信息中显示
+func (self *Parser) Deprecated(msg string) *Parser {
+	self.deprecated = msg
+	return self
+}
+
+// 帮助信息中是否隐藏
+func (self *Parser) isHidden() bool {
+	return self.hidden || self.deprecated != ""
+}
+
+// 按名称或别名查找子命令
+func (self *Parser) lookupSub(name string) (*Parser, bool) {
+	if p, ok := self.Subs[name]; ok {
+		return p, true
+	}
+	p, ok := self.aliasSubs[name]
+	return p, ok
+}
+
 func (self *Parser) SetDefaults(handler Handler) {
 	self.HandlerFunc = handler
 }
 
-// ./app upload -m test -c /tmp/conifg.json
-func (self *Parser) lookupParser(sources map[string]*Parser, cmds []string, options map[string]*Option) (*Parser, error) {
-	if sources == nil {
-		return nil, ERR_NotFound
+// 设置默认的配置文件路径，文件不存在时忽略
+func (self *Parser) SetConfigFile(path string) {
+	self.Root.configFile = path
+}
+
+// 设置为true时，配置文件中未知的key会报错，否则只打印警告
+func (self *Parser) SetConfigStrict(strict bool) {
+	self.Root.configStrict = strict
+}
+
+// 设置为true时，使用弃用的子命令或Option会报错，而不是输出警告，用于CI
+func (self *Parser) SetDeprecatedStrict(strict bool) {
+	self.Root.deprecatedStrict = strict
+}
+
+// 设置警告信息的输出，默认为os.Stderr
+func (self *Parser) SetErrOutput(w io.Writer) {
+	self.Root.errOutput = w
+}
+
+func (self *Parser) warnf(format string, a ...interface{}) {
+	w := self.root().errOutput
+	if w == nil {
+		w = os.Stderr
 	}
+	fmt.Fprintf(w, "warning: "+format+"\n", a...)
+}
 
-	for _, v := range self.Opts {
-		options[v.dest] = v
+// 使用了弃用的子命令或Option，严格模式下返回错误
+func (self *Parser) deprecate(format string, a ...interface{}) error {
+	if self.root().deprecatedStrict {
+		return fmt.Errorf(format, a...)
 	}
+	self.warnf(format, a...)
+	return nil
+}
 
-	if len(cmds) == 0 {
-		return self, nil
-	} else {
-		m := cmds[0]
-		if parser, ok := sources[m]; ok {
-			return parser.lookupParser(parser.Subs, cmds[1:], options)
-		}
-		return nil, ERR_NotFound
+// 完整的命令路径，比如 app upload part
+func (self *Parser) CommandPath() string {
+	if self.Super == nil {
+		return self.Name
 	}
+	return strings.TrimSpace(self.Super.CommandPath() + " " + self.Name)
 }
 
-// 逐层回溯父Parser的Opts
-func (self *Parser) getLongOption(parser *Parser, opt string) (option *Option, exists bool) {
-	option, exists = parser.LongOpts[opt]
-	if !exists {
-		if parser.Super == nil {
-			return
+// 只沿命令路径查找，不访问其他子命令；遇到不是子命令的参数时，如果当前命令有位置参数则停止查找
+func (self *Parser) findParser(sources map[string]*Parser, cmds []string) (*Parser, error) {
+	if sources == nil {
+		return nil, ERR_NotFound
+	}
+	parser := self
+	for _, m := range cmds {
+		p, ok := sources[m]
+		if !ok {
+			p, ok = parser.aliasSubs[m]
+		}
+		if !ok {
+			if len(parser.args) > 0 {
+				break
+			}
+			return nil, ERR_NotFound
 		}
-		return self.getLongOption(parser.Super, opt)
+		p.build()
+		parser, sources = p, p.Subs
 	}
-	return
+	return parser, nil
 }
 
-func (self *Parser) parseLongOption(opt string, params []string) (remain []string, err error) {
+// 当前命令支持的内置Option，比如 --help
+func (self *Parser) isBuiltinLong(opt string) bool {
+	switch opt {
+	case "help", "help-all", "print-config":
+		return true
+	case "version":
+		return self.versionInfo() != nil
+	}
+	return false
+}
+
+func (self *Parser) parseLongOption(cx *Context, opt string, params []string) (remain []string, err error) {
 	var value interface{}
 	opt = opt[2:]
 	remain = params
@@ -125,11 +282,23 @@ func (self *Parser) parseLongOption(opt string, params []string) (remain []strin
 	}
 	split := strings.SplitN(opt, "=", 2)
 	opt = split[0]
-	option, exists := self.getLongOption(self, opt)
-	if !exists {
-		if opt == "help" {
-			fmt.Println(self.getUsage())
-			err = ERR_Usage
+	// 内置的Option完整匹配时不参与缩写，比如 --version 不会匹配 --version-file
+	abbrev := self.root().allowAbbrev && !self.isBuiltinLong(opt)
+	option, err := self.compile().longOption(opt, abbrev)
+	if err != nil {
+		return
+	}
+	if option == nil {
+		if opt == "help" || opt == "help-all" {
+			err = self.printUsage(opt == "help-all")
+			return
+		}
+		if opt == "version" && self.versionInfo() != nil {
+			err = self.printVersion(false)
+			return
+		}
+		if opt == "print-config" {
+			err = errPrintConfig
 			return
 		}
 		// skip unknown option ?
@@ -139,9 +308,9 @@ func (self *Parser) parseLongOption(opt string, params []string) (remain []strin
 	if len(split) == 2 {
 		// '--flag=arg'
 		value = split[1]
-	} else if option.setBool {
+	} else if !option.needValue() {
 		// '--flag' (arg was optional)
-		value = option.boolV
+		value = option.flagValue()
 	} else if len(remain) > 0 {
 		value = remain[0]
 		remain = remain[1:]
@@ -150,34 +319,24 @@ func (self *Parser) parseLongOption(opt string, params []string) (remain []strin
 		err = errors.New(fmt.Sprintf("Flag needs an argument: --%s", opt))
 		return
 	}
-	err = option.parse(value)
+	err = option.apply(cx, value)
 	return
 }
 
-// 逐层回溯父Parser的Opts
-func (self *Parser) getShortOption(paser *Parser, opt rune) (option *Option, exists bool) {
-	option, exists = paser.ShortOpts[opt]
-	if !exists {
-		if paser.Super == nil {
-			return
-		}
-		return self.getShortOption(paser.Super, opt)
-	}
-	return
-}
-
-func (self *Parser) parserSingleShortOption(opt string, params []string) (outOpt string, remain []string, err error) {
+func (self *Parser) parserSingleShortOption(cx *Context, opt string, params []string) (outOpt string, remain []string, err error) {
 	remain = params
 	outOpt = opt[1:]
 
 	c := opt[0]
-	option, exists := self.getShortOption(self, rune(c))
+	option, exists := self.compile().short[rune(c)]
 
 	if !exists {
 		switch {
 		case c == 'h':
-			fmt.Println(self.getUsage())
-			err = ERR_Usage
+			err = self.printUsage(false)
+			return
+		case c == 'V' && self.versionInfo() != nil:
+			err = self.printVersion(false)
 			return
 		default:
 			err = errors.New(fmt.Sprintf("Unknown short flag: %q in -%s", c, opt))
@@ -189,9 +348,9 @@ func (self *Parser) parserSingleShortOption(opt string, params []string) (outOpt
 	if len(opt) > 2 && opt[1] == '=' {
 		value = opt[2:]
 		outOpt = ""
-	} else if option.setBool {
+	} else if !option.needValue() {
 		// '-f' (arg was optional)
-		value = option.boolV
+		value = option.flagValue()
 		outOpt = ""
 	} else if len(opt) > 1 {
 		value = opt[1:]
@@ -204,12 +363,12 @@ func (self *Parser) parserSingleShortOption(opt string, params []string) (outOpt
 		return
 	}
 
-	err = option.parse(value)
+	err = option.apply(cx, value)
 	return
 
 }
 
-func (self *Parser) parseShortOption(opt string, params []string) (remain []string, err error) {
+func (self *Parser) parseShortOption(cx *Context, opt string, params []string) (remain []string, err error) {
 	opt = opt[1:]
 	remain = params
 
@@ -218,7 +377,7 @@ func (self *Parser) parseShortOption(opt string, params []string) (remain []stri
 	// Thus, ‘-abc’ is equivalent to ‘-a -b -c’.
 	// short opt can bo a series of opt letters of flags (e.g "-abc")
 	for len(opt) > 0 {
-		opt, remain, err = self.parserSingleShortOption(opt, params)
+		opt, remain, err = self.parserSingleShortOption(cx, opt, params)
 		if err != nil {
 			return
 		}
@@ -226,25 +385,42 @@ func (self *Parser) parseShortOption(opt string, params []string) (remain []stri
 	return
 }
 
-func (self *Parser) bindParams(params []string) (err error) {
+// 解析Option，不以-开头的参数以及 -- 之后的参数作为位置参数
+func (self *Parser) bindParams(cx *Context, params []string) (err error) {
+	explain := false
+	defer func() {
+		if err == nil && explain {
+			err = errPrintConfig
+		}
+	}()
+
+	args := []string{}
 	for len(params) > 0 {
 		s := params[0]
 		params = params[1:]
 
+		if len(s) < 2 || s[0] != '-' {
+			args = append(args, s)
+			continue
+		}
 		// 样式：--name
 		if s[1] == '-' {
 			if len(s) == 2 { // --
-				return errors.New("NotSupport")
+				args = append(args, params...)
+				break
 			}
-			params, err = self.parseLongOption(s, params)
+			params, err = self.parseLongOption(cx, s, params)
 		} else {
-			params, err = self.parseShortOption(s, params)
+			params, err = self.parseShortOption(cx, s, params)
+		}
+		if err == errPrintConfig {
+			explain, err = true, nil
 		}
 		if err != nil {
 			return
 		}
 	}
-	return
+	return self.bindArgs(cx, args)
 }
 
 func (self *Parser) getCmdsAndParams(input []string) (cmds []string, params []string) {
@@ -260,30 +436,8 @@ func (self *Parser) getCmdsAndParams(input []string) (cmds []string, params []st
 	return
 }
 
-// 前置动作，填写默认参数，默认Dest等
-func (self *Parser) preFilterAllOption() {
-	for _, v := range self.Opts {
-		v.pre()
-	}
-
-	for _, v := range self.Subs {
-		v.preFilterAllOption()
-	}
-	return
-}
-
-func (self *Parser) postFilterAllOption() (err error) {
-	for _, v := range self.Opts {
-		err = v.post()
-	}
-
-	for _, v := range self.Subs {
-		err = v.postFilterAllOption()
-	}
-
-	return
-}
-
+// 解析参数，解析状态保存在Result中，同一个Parser可以重复或并发调用
+// 注意：StringVar等绑定的变量由所有调用共享
 func (self *Parser) ParseArgs(input []string) (result *Result) {
 	var err error
 	var parser *Parser
@@ -297,33 +451,89 @@ func (self *Parser) ParseArgs(input []string) (result *Result) {
 		HandlerFunc: self.HandlerFunc,
 	}
 
-	// Pre操作, 设置默认的longV等
-	self.preFilterAllOption()
-
 	// 解析为对应的参数
 	// 先找到method，然后根据method检查对应的参数
 	cmds, params := self.getCmdsAndParams(input)
 
-	// 查找对应的Parser
-	options := map[string]*Option{}
-	if parser, err = self.lookupParser(self.Subs, cmds, options); err != nil {
+	// 内置的help子命令
+	if _, ok := self.lookupSub("help"); !ok && len(cmds) > 0 && cmds[0] == "help" {
+		result.err = self.printHelpCommand(cmds[1:], params)
+		return
+	}
+	// 内置的version子命令
+	if _, ok := self.lookupSub("version"); !ok && len(cmds) == 1 && cmds[0] == "version" && self.versionInfo() != nil {
+		result.err = self.printVersionCommand(params)
+		return
+	}
+
+	// 查找对应的Parser，只编译当前命令路径的查找表
+	if parser, err = self.findParser(self.Subs, cmds); err != nil {
+		result.err = err
+		return
+	}
+	options := parser.compile().options
+	// 子命令之后的参数为位置参数
+	args := cmds[len(parser.path())-len(self.path()):]
+
+	// 定义时发现的错误
+	if err = self.Err(); err != nil {
 		result.err = err
 		return
 	}
+
+	// 弃用的子命令
+	for _, p := range parser.path() {
+		if p.deprecated == "" {
+			continue
+		}
+		if err = self.deprecate("Command '%s' is deprecated: %s", p.CommandPath(), p.deprecated); err != nil {
+			result.err = err
+			return
+		}
+	}
+
 	result.Title = parser.Title
+	result.parser = parser
+	result.HandlerFunc = parser.HandlerFunc
 	cx.options = options
 
 	// 执行参数检查和绑定
-	if err = parser.bindParams(params); err != nil {
+	explain := false
+	if err = parser.bindParams(cx, append(args, params...)); err == errPrintConfig {
+		explain = true
+	} else if err != nil {
 		result.err = err
 		return
 	}
 
-	// Post 操作，检查必选等
-	if err = self.postFilterAllOption(); err != nil {
+	// 读取环境变量和配置文件
+	if err = self.applySources(cx); err != nil {
 		result.err = err
 		return
 	}
+
+	// 缺少必选参数时依然输出，显示为 (unset)
+	if explain {
+		fmt.Print(cx.explain())
+		result.err = ERR_Usage
+		return
+	}
+
+	// Post 操作，检查必选等，只处理当前命令路径上的Option
+	for _, v := range options {
+		if err = cx.value(v).post(); err != nil {
+			result.err = err
+			return
+		}
+	}
+
+	// 回写绑定的变量
+	for _, v := range options {
+		if err = cx.value(v).bind(); err != nil {
+			result.err = err
+			return
+		}
+	}
 	return
 }
 
@@ -339,51 +549,72 @@ func (self *Result) Handle() (err error) {
 	return
 }
 
+// 执行Handler，出错时输出错误并退出，ERR_Usage时正常退出
 func (self *Result) HandleError() {
-	self.Handle()
-	if self.err != nil {
-		switch self.err {
+	if err := self.Handle(); err != nil {
+		switch err {
 		case ERR_Usage:
-			os.Exit(0)
+			osExit(0)
 		default:
-			fmt.Printf("err: %s\n", self.err)
-			os.Exit(1)
+			fmt.Printf("err: %s\n", err)
+			osExit(1)
 		}
 	}
 }
 
 // for usage
 func (self *Parser) OptionText() string {
+	return self.optionText(helpStyle{})
+}
+
+// 用法中命令路径之后的部分，比如 [global options] [-f/--file FILE] <src>... <command>
+func (self *Parser) optionText(s helpStyle) string {
 	tmp := []string{}
 	// 开始位置
 	var startPoint int
-	startPoint = 3 + len(self.Root.Name)
+	startPoint = 3 + displayWidth(self.CommandPath())
 
-	for k, v := range self.Opts {
+	if len(visibleOptions(self.globalOptions(), s.all)) > 0 {
+		tmp = append(tmp, "[global options]")
+	}
+	for _, v := range self.orderedOptions() {
+		if v.isHidden() && !s.all {
+			continue
+		}
 		if v.setBool {
 			tmp = append(tmp, "["+v.getOptString()+"]")
 		} else {
 			if !v.requiredV {
-				tmp = append(tmp, "["+v.getOptString()+" "+strings.ToUpper(k)+"]")
+				tmp = append(tmp, "["+v.getOptString()+" "+v.metavar(s)+"]")
 			} else {
-				tmp = append(tmp, v.getOptString()+" "+strings.ToUpper(k))
+				tmp = append(tmp, v.getOptString()+" "+v.metavar(s))
 			}
 		}
 
 	}
+	for _, v := range self.args {
+		tmp = append(tmp, v.argString())
+	}
+	for _, v := range self.Subs {
+		if s.all || !v.isHidden() {
+			tmp = append(tmp, "<command>")
+			break
+		}
+	}
 
-	sort.Strings(tmp)
-
-	count := 0
+	// 超过宽度时换行，续行与第一个Option对齐
+	width := s.lineWidth()
+	col := startPoint + 2
 	out := []string{}
-	for _, s := range tmp {
-		if count == 2 {
+	for i, item := range tmp {
+		n := displayWidth(item)
+		if i > 0 && col+n > width {
 			out = append(out, "\n")
 			out = append(out, strings.Repeat(" ", startPoint))
-			count = 0
+			col = startPoint + 2
 		}
-		out = append(out, s)
-		count++
+		out = append(out, item)
+		col += n + 1
 	}
 
 	return strings.Join(out, " ")
@@ -391,45 +622,76 @@ func (self *Parser) OptionText() string {
 
 // TODO 格式化
 func (self *Parser) OptionDetailText() string {
+	return self.optionDetailText(helpStyle{})
+}
+
+func (self *Parser) optionDetailText(s helpStyle) string {
+	return groupedOptionText(visibleOptions(self.orderedOptions(), s.all), self.orderedGroups(), s)
+}
+
+// 继承自父Parser的Option
+func (self *Parser) GlobalOptionDetailText() string {
+	return self.globalOptionDetailText(helpStyle{})
+}
+
+func (self *Parser) globalOptionDetailText(s helpStyle) string {
+	return optionDetailText(visibleOptions(self.globalOptions(), s.all), s)
+}
+
+// 过滤隐藏的Option
+func visibleOptions(opts []*Option, all bool) []*Option {
+	out := []*Option{}
+	for _, v := range opts {
+		if all || !v.isHidden() {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// 父Parser中被当前Parser继承的Option，不包括本地Option以及被当前Parser覆盖的dest
+func (self *Parser) globalOptions() []*Option {
+	opts := []*Option{}
+	seen := map[string]bool{}
+	for _, v := range self.Opts {
+		seen[v.dest] = true
+	}
+	for p := self.Super; p != nil; p = p.Super {
+		for _, v := range p.orderedOptions() {
+			if v.local || seen[v.dest] {
+				continue
+			}
+			seen[v.dest] = true
+			opts = append(opts, v)
+		}
+	}
+	return opts
+}
+
+func optionDetailText(opts []*Option, s helpStyle) string {
 	buf := new(bytes.Buffer)
 	prefixs := []string{}
-	maxlen := 0
+	helps := []string{}
 
-	for _, v := range self.Opts {
-		line := ""
+	for _, v := range opts {
+		line := v.flags()
 		if v.shortV != 0 {
 			if v.setBool {
 				sOpt := fmt.Sprintf("-%c", v.shortV)
 				line = fmt.Sprintf("%4s", sOpt)
 			} else {
-				sOpt := fmt.Sprintf("-%c", v.shortV)
-				line = fmt.Sprintf("%4s, --%s", sOpt, v.longV)
+				line = "  " + line
 			}
-
 		} else {
-			line = fmt.Sprintf("--%s", v.longV)
+			// 与 -m, --mode 中的long对齐
+			line = "      " + line
 		}
 
-		if len(line) > maxlen {
-			maxlen = len(line)
-		}
 		prefixs = append(prefixs, line)
+		helps = append(helps, v.detailHelp(s))
 	}
 
-	f := fmt.Sprintf("%%-%ds", maxlen)
-
-	lines := []string{}
-	index := 0
-	for _, v := range self.Opts {
-		line := prefixs[index]
-		out := fmt.Sprintf(f+" %s", line, v.help)
-		lines = append(lines, out)
-		index++
-	}
-
-	sort.Strings(lines)
-
-	for _, line := range lines {
+	for _, line := range alignRows(prefixs, helps, s.lineWidth()) {
 		fmt.Fprintln(buf, line)
 	}
 
@@ -437,47 +699,48 @@ func (self *Parser) OptionDetailText() string {
 }
 
 func (self *Parser) SubCommandText() string {
+	return self.subCommandText(helpStyle{})
+}
+
+func (self *Parser) subCommandText(s helpStyle) string {
 	buf := new(bytes.Buffer)
-	lines := []string{}
-	maxlen := 0
-	for _, v := range self.Subs {
-		if len(v.Title) > maxlen {
-			maxlen = len(v.Title)
+	subs := []*Parser{}
+	titles := []string{}
+	helps := []string{}
+	for _, v := range self.orderedSubs() {
+		if v.isHidden() && !s.all {
+			continue
+		}
+		subs = append(subs, v)
+		titles = append(titles, v.displayTitle())
+		helps = append(helps, v.summary())
+	}
+	rows := alignRows(titles, helps, s.lineWidth())
+	for _, category := range subCategories(subs) {
+		lines := []string{}
+		for i, v := range subs {
+			if v.category == category {
+				lines = append(lines, rows[i])
+			}
+		}
+		if category != "" {
+			if buf.Len() > 0 {
+				fmt.Fprintln(buf)
+			}
+			fmt.Fprintf(buf, "%s:\n", category)
+		}
+		for _, line := range lines {
+			fmt.Fprintln(buf, line)
 		}
-	}
-	f := fmt.Sprintf("%%-%ds", maxlen)
-	for _, v := range self.Subs {
-		line := fmt.Sprintf(f+" %s", v.Title, v.Help)
-		lines = append(lines, line)
-	}
-
-	sort.Strings(lines)
-
-	for _, line := range lines {
-		fmt.Fprintln(buf, line)
 	}
 
 	return buf.String()
 }
 
-func (self *Parser) getUsage() string {
-	var b bytes.Buffer
-	var tmpl string
-
-	tmpl = `{{ .Help }}
-
-Usage:
-    {{ .Root.Name }} {{ .OptionText }} {{ with .OptionDetailText}}
-
-Options:
-{{.}}{{end}} {{ with .SubCommandText}}
-SubCommands:
-{{.}}{{end}}
-`
-
-	t := template.New("top")
-	template.Must(t.Parse(tmpl))
-	t.Execute(&b, &self)
-
-	return b.String()
+// 子命令列表中显示的名称，比如 remove (rm, del)
+func (self *Parser) displayTitle() string {
+	if len(self.aliases) == 0 {
+		return self.Title
+	}
+	return fmt.Sprintf("%s (%s)", self.Title, strings.Join(self.aliases, ", "))
 }
@@ -1,7 +1,11 @@
 package goargs
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -30,7 +34,7 @@ func TestParseLongOptionErrMissValue(t *testing.T) {
 	params := []string{"--mode"}
 	parser = ArgumentParser("", "")
 	parser.AddOption("mode", "mode help").Long("mode")
-	_, err = parser.parseLongOption(params[0], params[1:])
+	_, err = parser.parseLongOption(nil, params[0], params[1:])
 
 	if err == nil {
 		t.Error()
@@ -43,6 +47,7 @@ func TestParseLongOptionErrMissValue(t *testing.T) {
 }
 
 func TestParseLongOptionBasicWithBoolTrue(t *testing.T) {
+	cx := &Context{}
 	var err error
 	var parser *Parser
 	var p *Option
@@ -51,8 +56,8 @@ func TestParseLongOptionBasicWithBoolTrue(t *testing.T) {
 	parser = ArgumentParser("", "")
 	// 表示在命令行中，如果声明了Flag，但是没有传入参数，那么就采用Bool中设置的值
 	parser.AddOption("force", "force do something").Bool(true)
-	parser.preFilterAllOption()
-	_, err = parser.parseLongOption(params[0], params[1:])
+	parser.Compile()
+	_, err = parser.parseLongOption(cx, params[0], params[1:])
 
 	if err != nil {
 		t.Error(err)
@@ -64,12 +69,13 @@ func TestParseLongOptionBasicWithBoolTrue(t *testing.T) {
 		t.Error()
 	}
 
-	if p.getBool() != true {
+	if cx.value(p).getBool() != true {
 		t.Error()
 	}
 }
 
 func TestParseLongOptionBasicWithBoolFalse(t *testing.T) {
+	cx := &Context{}
 	var err error
 	var parser *Parser
 	var p *Option
@@ -78,8 +84,8 @@ func TestParseLongOptionBasicWithBoolFalse(t *testing.T) {
 	parser = ArgumentParser("", "")
 	// 表示在命令行中，如果声明了Flag，但是没有传入参数，那么就采用Bool中设置的值
 	parser.AddOption("force", "force do something").Bool(false)
-	parser.preFilterAllOption()
-	_, err = parser.parseLongOption(params[0], params[1:])
+	parser.Compile()
+	_, err = parser.parseLongOption(cx, params[0], params[1:])
 
 	if err != nil {
 		t.Error(err)
@@ -91,12 +97,13 @@ func TestParseLongOptionBasicWithBoolFalse(t *testing.T) {
 		t.Error()
 	}
 
-	if p.getBool() != false {
+	if cx.value(p).getBool() != false {
 		t.Error()
 	}
 }
 
 func TestParseLongOptionBasic(t *testing.T) {
+	cx := &Context{}
 	var remain []string
 	var err error
 	var parser *Parser
@@ -104,7 +111,7 @@ func TestParseLongOptionBasic(t *testing.T) {
 	params := []string{"--mode=test", "-p"}
 	parser = ArgumentParser("", "")
 	parser.AddOption("mode", "mode help").Long("mode")
-	remain, err = parser.parseLongOption(params[0], params[1:])
+	remain, err = parser.parseLongOption(cx, params[0], params[1:])
 
 	if err != nil {
 		t.Error()
@@ -120,7 +127,7 @@ func TestParseLongOptionBasic(t *testing.T) {
 		t.Error()
 	}
 
-	v := p.getString()
+	v := cx.value(p).getString()
 
 	if v != "test" {
 		t.Error(v)
@@ -128,6 +135,7 @@ func TestParseLongOptionBasic(t *testing.T) {
 }
 
 func TestParseLongOptionBasicWithSpace(t *testing.T) {
+	cx := &Context{}
 	var remain []string
 	var err error
 	var parser *Parser
@@ -135,7 +143,7 @@ func TestParseLongOptionBasicWithSpace(t *testing.T) {
 	params := []string{"--mode", "test", "-p"}
 	parser = ArgumentParser("", "")
 	parser.AddOption("mode", "mode help").Long("mode")
-	remain, err = parser.parseLongOption(params[0], params[1:])
+	remain, err = parser.parseLongOption(cx, params[0], params[1:])
 
 	if err != nil {
 		t.Error()
@@ -151,7 +159,7 @@ func TestParseLongOptionBasicWithSpace(t *testing.T) {
 		t.Error()
 	}
 
-	v := p.getString()
+	v := cx.value(p).getString()
 
 	if v != "test" {
 		t.Error(v)
@@ -159,6 +167,7 @@ func TestParseLongOptionBasicWithSpace(t *testing.T) {
 }
 
 func TestParseLongOptionBasicOptInSuperParser(t *testing.T) {
+	cx := &Context{}
 	var remain []string
 	var err error
 	var parser *Parser
@@ -174,7 +183,7 @@ func TestParseLongOptionBasicOptInSuperParser(t *testing.T) {
 	upload.AddOption("name", "name of file.").Long("name")
 
 	// 解析参数
-	remain, err = upload.parseLongOption(params[1], params[2:])
+	remain, err = upload.parseLongOption(cx, params[1], params[2:])
 
 	if err != nil {
 		t.Error()
@@ -191,21 +200,21 @@ func TestParseLongOptionBasicOptInSuperParser(t *testing.T) {
 		t.Error()
 	}
 
-	v = p.getString()
+	v = cx.value(p).getString()
 
 	if v != "test" {
 		t.Error(v)
 	}
 
 	//
-	remain, err = upload.parseLongOption(remain[0], remain[1:])
+	remain, err = upload.parseLongOption(cx, remain[0], remain[1:])
 	p, exists = upload.Opts["name"]
 
 	if !exists {
 		t.Error()
 	}
 
-	v = p.getString()
+	v = cx.value(p).getString()
 
 	if v != "readme.md" {
 		t.Error(v)
@@ -213,6 +222,7 @@ func TestParseLongOptionBasicOptInSuperParser(t *testing.T) {
 }
 
 func TestParseLongOptionBasicOptInSuperSuperParser(t *testing.T) {
+	cx := &Context{}
 	var remain []string
 	var err error
 	var parser *Parser
@@ -232,7 +242,7 @@ func TestParseLongOptionBasicOptInSuperSuperParser(t *testing.T) {
 	uploadFile.AddOption("len", "len of file.").Long("len")
 
 	// 解析参数
-	remain, err = uploadFile.parseLongOption(params[2], params[3:])
+	remain, err = uploadFile.parseLongOption(cx, params[2], params[3:])
 
 	if err != nil {
 		t.Error()
@@ -249,48 +259,48 @@ func TestParseLongOptionBasicOptInSuperSuperParser(t *testing.T) {
 		t.Error()
 	}
 
-	v = p.getString()
+	v = cx.value(p).getString()
 
 	if v != "test" {
 		t.Error(v)
 	}
 
 	//
-	remain, err = uploadFile.parseLongOption(remain[0], remain[1:])
+	remain, err = uploadFile.parseLongOption(cx, remain[0], remain[1:])
 	p, exists = upload.Opts["name"]
 
 	if !exists {
 		t.Error()
 	}
 
-	v = p.getString()
+	v = cx.value(p).getString()
 
 	if v != "readme.md" {
 		t.Error(v)
 	}
 
 	//
-	remain, err = uploadFile.parseLongOption(remain[0], remain[1:])
+	remain, err = uploadFile.parseLongOption(cx, remain[0], remain[1:])
 	p, exists = uploadFile.Opts["len"]
 
 	if !exists {
 		t.Error()
 	}
 
-	v = p.getString()
+	v = cx.value(p).getString()
 
 	if v != "178" {
 		t.Error(v)
 	}
 }
 
-func TestPreFilterAllOption(t *testing.T) {
+func TestPreFilterOptions(t *testing.T) {
 	//var err error
 	var parser *Parser
 
 	parser = ArgumentParser("", "")
 	parser.AddOption("mode", "mode type")
-	parser.preFilterAllOption()
+	parser.Compile()
 
 	if len(parser.Opts) != 1 {
 		t.Errorf("Expect the Opts length 1 but %d", len(parser.Opts))
@@ -311,13 +321,13 @@ func TestPreFilterAllOption(t *testing.T) {
 	}
 }
 
-func TestPreFilterAllOptionWithShort(t *testing.T) {
+func TestPreFilterOptionsWithShort(t *testing.T) {
 	//var err error
 	var parser *Parser
 
 	parser = ArgumentParser("", "")
 	parser.AddOption("mode", "mode type").Short('m')
-	parser.preFilterAllOption()
+	parser.Compile()
 
 	if len(parser.Opts) != 1 {
 		t.Errorf("Expect the Opts length 1 but %d", len(parser.Opts))
@@ -401,7 +411,7 @@ func TestGetCmdsAndParamsWithMultiCmds(t *testing.T) {
 	}
 }
 
-func TestLookupParser(t *testing.T) {
+func TestFindParser(t *testing.T) {
 	var err error
 	var parser *Parser
 
@@ -409,12 +419,11 @@ func TestLookupParser(t *testing.T) {
 
 	parser = ArgumentParser("root", "help")
 	parser.AddOption("mode", "mode type")
-	parser.preFilterAllOption()
-	options := map[string]*Option{}
+	parser.Compile()
 
 	var tmp *Parser
-	if tmp, err = parser.lookupParser(parser.Subs, input, options); err != nil {
-		t.Error(err)
+	if tmp, err = parser.findParser(parser.Subs, input); err != nil {
+		t.Fatal(err)
 	}
 
 	if tmp.Name != "root" {
@@ -422,7 +431,7 @@ func TestLookupParser(t *testing.T) {
 	}
 }
 
-func TestLookupParserSub(t *testing.T) {
+func TestFindParserSub(t *testing.T) {
 	var err error
 	var parser *Parser
 
@@ -433,11 +442,10 @@ func TestLookupParserSub(t *testing.T) {
 
 	sub := parser.AddParser("upload", "upload help")
 	sub.AddOption("path", "path help")
-	options := map[string]*Option{}
 
 	var tmp *Parser
-	if tmp, err = parser.lookupParser(parser.Subs, input, options); err != nil {
-		t.Error(err)
+	if tmp, err = parser.findParser(parser.Subs, input); err != nil {
+		t.Fatal(err)
 	}
 
 	if tmp.Title != "upload" {
@@ -445,7 +453,7 @@ func TestLookupParserSub(t *testing.T) {
 	}
 }
 
-func TestLookupParserSubSub(t *testing.T) {
+func TestFindParserSubSub(t *testing.T) {
 	var err error
 	var parser *Parser
 
@@ -460,11 +468,9 @@ func TestLookupParserSubSub(t *testing.T) {
 	subsub := sub.AddParser("check", "upload check help")
 	subsub.AddOption("ischeck", "ischeck help")
 
-	options := map[string]*Option{}
-
 	var tmp *Parser
-	if tmp, err = parser.lookupParser(parser.Subs, input, options); err != nil {
-		t.Error(err)
+	if tmp, err = parser.findParser(parser.Subs, input); err != nil {
+		t.Fatal(err)
 	}
 
 	if tmp.Title != "check" {
@@ -472,7 +478,7 @@ func TestLookupParserSubSub(t *testing.T) {
 	}
 }
 
-func TestLookupParserErrorSub(t *testing.T) {
+func TestFindParserErrorSub(t *testing.T) {
 	var err error
 	var parser *Parser
 
@@ -484,14 +490,12 @@ func TestLookupParserErrorSub(t *testing.T) {
 	sub := parser.AddParser("upload", "upload help")
 	sub.AddOption("path", "path help")
 
-	options := map[string]*Option{}
-
-	if _, err = parser.lookupParser(parser.Subs, input, options); err == nil {
+	if _, err = parser.findParser(parser.Subs, input); err == nil {
 		t.Error("Excpect can not found the parser")
 	}
 }
 
-func TestLookupParserWithErrSub(t *testing.T) {
+func TestFindParserWithErrSub(t *testing.T) {
 	var err error
 	var parser *Parser
 
@@ -506,14 +510,12 @@ func TestLookupParserWithErrSub(t *testing.T) {
 	subsub := sub.AddParser("check", "upload check help")
 	subsub.AddOption("ischeck", "ischeck help")
 
-	options := map[string]*Option{}
-
-	if _, err = parser.lookupParser(parser.Subs, input, options); err == nil {
+	if _, err = parser.findParser(parser.Subs, input); err == nil {
 		t.Error("Excpect can not found the parser")
 	}
 }
 
-func TestLookupParserWithErrSubSub(t *testing.T) {
+func TestFindParserWithErrSubSub(t *testing.T) {
 	var err error
 	var parser *Parser
 
@@ -528,14 +530,12 @@ func TestLookupParserWithErrSubSub(t *testing.T) {
 	subsub := sub.AddParser("check", "upload check help")
 	subsub.AddOption("ischeck", "ischeck help")
 
-	options := map[string]*Option{}
-
-	if _, err = parser.lookupParser(parser.Subs, input, options); err == nil {
+	if _, err = parser.findParser(parser.Subs, input); err == nil {
 		t.Error("Excpect can not found the parser")
 	}
 }
 
-func TestLookupParserMultiSub(t *testing.T) {
+func TestFindParserMultiSub(t *testing.T) {
 	var err error
 	var parser *Parser
 
@@ -549,12 +549,12 @@ func TestLookupParserMultiSub(t *testing.T) {
 	download.AddOption("path", "download path help")
 
 	{
-		options := map[string]*Option{}
 		input := []string{"upload"}
 		var tmp *Parser
-		if tmp, err = parser.lookupParser(parser.Subs, input, options); err != nil {
-			t.Error(err)
+		if tmp, err = parser.findParser(parser.Subs, input); err != nil {
+			t.Fatal(err)
 		}
+		options := tmp.compile().options
 
 		if tmp.Title != "upload" {
 			t.Errorf("Expect the Title is 'upload' but '%s'", tmp.Title)
@@ -582,12 +582,12 @@ func TestLookupParserMultiSub(t *testing.T) {
 		}
 	}
 	{
-		options := map[string]*Option{}
 		input := []string{"download"}
 		var tmp *Parser
-		if tmp, err = parser.lookupParser(parser.Subs, input, options); err != nil {
-			t.Error(err)
+		if tmp, err = parser.findParser(parser.Subs, input); err != nil {
+			t.Fatal(err)
 		}
+		options := tmp.compile().options
 
 		assertEqual(t, "download", tmp.Title)
 		assertEqualInt(t, 2, len(options))
@@ -624,11 +624,10 @@ func TestBindParams(t *testing.T) {
 	download := parser.AddParser("download", "download help")
 	download.AddOption("path", "download path help")
 
-	options := map[string]*Option{}
+	tmpParser, _ := parser.findParser(parser.Subs, []string{"upload"})
+	options := tmpParser.compile().options
 
-	tmpParser, _ := parser.lookupParser(parser.Subs, []string{"upload"}, options)
-
-	err = tmpParser.bindParams([]string{})
+	err = tmpParser.bindParams(&Context{options: options}, []string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -647,13 +646,13 @@ func TestBindParamsWithValidArgs(t *testing.T) {
 	download := parser.AddParser("download", "download help")
 	download.AddOption("path", "download path help")
 
-	parser.preFilterAllOption()
-
-	options := map[string]*Option{}
+	parser.Compile()
 
-	tmpParser, _ := parser.lookupParser(parser.Subs, []string{"upload"}, options)
+	tmpParser, _ := parser.findParser(parser.Subs, []string{"upload"})
+	options := tmpParser.compile().options
+	cx := &Context{options: options}
 
-	err = tmpParser.bindParams([]string{"--mode=test", "-p", "/home/admin"})
+	err = tmpParser.bindParams(cx, []string{"--mode=test", "-p", "/home/admin"})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -662,14 +661,14 @@ func TestBindParamsWithValidArgs(t *testing.T) {
 		if o, ok := options["mode"]; !ok {
 			t.Fatal()
 		} else {
-			assertEqual(t, "test", o.getString())
+			assertEqual(t, "test", cx.value(o).getString())
 		}
 	}
 	{
 		if o, ok := options["path"]; !ok {
 			t.Fatal()
 		} else {
-			assertEqual(t, "/home/admin", o.getString())
+			assertEqual(t, "/home/admin", cx.value(o).getString())
 		}
 	}
 }
@@ -687,11 +686,10 @@ func TestBindParamsWithInvalidArgs(t *testing.T) {
 	download := parser.AddParser("download", "download help")
 	download.AddOption("path", "download path help")
 
-	options := map[string]*Option{}
-
-	tmpParser, _ := parser.lookupParser(parser.Subs, []string{"upload"}, options)
+	tmpParser, _ := parser.findParser(parser.Subs, []string{"upload"})
+	options := tmpParser.compile().options
 
-	err = tmpParser.bindParams([]string{"--modex=test"})
+	err = tmpParser.bindParams(&Context{options: options}, []string{"--modex=test"})
 	if err == nil {
 		t.Fatal()
 	}
@@ -711,11 +709,10 @@ func TestBindParamsWithValidShortArgs(t *testing.T) {
 	download := parser.AddParser("download", "download help")
 	download.AddOption("path", "download path help").Short('p')
 
-	options := map[string]*Option{}
+	tmpParser, _ := parser.findParser(parser.Subs, []string{"upload"})
+	options := tmpParser.compile().options
 
-	tmpParser, _ := parser.lookupParser(parser.Subs, []string{"upload"}, options)
-
-	err = tmpParser.bindParams([]string{"-m test"})
+	err = tmpParser.bindParams(&Context{options: options}, []string{"-m test"})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -734,18 +731,17 @@ func TestBindParamsWithInvalidShortArgs(t *testing.T) {
 	download := parser.AddParser("download", "download help")
 	download.AddOption("path", "download path help").Short('p')
 
-	options := map[string]*Option{}
-
-	tmpParser, _ := parser.lookupParser(parser.Subs, []string{"upload"}, options)
+	tmpParser, _ := parser.findParser(parser.Subs, []string{"upload"})
+	options := tmpParser.compile().options
 
-	err = tmpParser.bindParams([]string{"-x test"})
+	err = tmpParser.bindParams(&Context{options: options}, []string{"-x test"})
 	if err == nil {
 		t.Fatal()
 	}
 	assertEqual(t, "Unknown short flag: 'x' in -x test", err.Error())
 }
 
-func TestPostFilterAllOption(t *testing.T) {
+func TestPostOptions(t *testing.T) {
 	var err error
 	var parser *Parser
 
@@ -758,21 +754,25 @@ func TestPostFilterAllOption(t *testing.T) {
 	download := parser.AddParser("download", "download help")
 	download.AddOption("path", "download path help").Short('p').Required()
 
-	parser.preFilterAllOption()
+	parser.Compile()
 
-	options := map[string]*Option{}
+	tmpParser, _ := parser.findParser(parser.Subs, []string{"upload"})
+	options := tmpParser.compile().options
+	cx := &Context{options: options}
 
-	tmpParser, _ := parser.lookupParser(parser.Subs, []string{"upload"}, options)
+	tmpParser.bindParams(cx, []string{"-m test", "-p /home/admin"})
 
-	tmpParser.bindParams([]string{"-m test", "-p /home/admin"})
-
-	err = tmpParser.postFilterAllOption()
+	for _, v := range options {
+		if err = cx.value(v).post(); err != nil {
+			break
+		}
+	}
 	if err != nil {
 		t.Fatal(err)
 	}
 }
 
-func TestPostFilterAllOptionNoValue(t *testing.T) {
+func TestPostOptionsNoValue(t *testing.T) {
 	var err error
 	var parser *Parser
 
@@ -785,22 +785,26 @@ func TestPostFilterAllOptionNoValue(t *testing.T) {
 	download := parser.AddParser("download", "download help")
 	download.AddOption("path", "download path help").Short('p').Required()
 
-	parser.preFilterAllOption()
-
-	options := map[string]*Option{}
+	parser.Compile()
 
-	tmpParser, _ := parser.lookupParser(parser.Subs, []string{"upload"}, options)
+	tmpParser, _ := parser.findParser(parser.Subs, []string{"upload"})
+	options := tmpParser.compile().options
+	cx := &Context{options: options}
 
-	tmpParser.bindParams([]string{"-m test"})
+	tmpParser.bindParams(cx, []string{"-m test"})
 
-	err = tmpParser.postFilterAllOption()
+	for _, v := range options {
+		if err = cx.value(v).post(); err != nil {
+			break
+		}
+	}
 	if err == nil {
 		t.Fatal()
 	}
 	assertEqual(t, "Missing required option: '-p'", err.Error())
 }
 
-func TestPostFilterAllOptionInvalidValue(t *testing.T) {
+func TestPostOptionsInvalidValue(t *testing.T) {
 	var err error
 	var parser *Parser
 
@@ -813,13 +817,12 @@ func TestPostFilterAllOptionInvalidValue(t *testing.T) {
 	download := parser.AddParser("download", "download help")
 	download.AddOption("path", "download path help").Short('p').Required()
 
-	parser.preFilterAllOption()
+	parser.Compile()
 
-	options := map[string]*Option{}
+	tmpParser, _ := parser.findParser(parser.Subs, []string{})
+	options := tmpParser.compile().options
 
-	tmpParser, _ := parser.lookupParser(parser.Subs, []string{}, options)
-
-	err = tmpParser.bindParams([]string{"-m test", "-p /home/admin"})
+	err = tmpParser.bindParams(&Context{options: options}, []string{"-m test", "-p /home/admin"})
 	if err == nil {
 		t.Fatal()
 	}
@@ -904,3 +907,262 @@ func Test_FT_bool(t *testing.T) {
 func Test_FT_flag(t *testing.T) {
 
 }
+
+func TestLocalOption(t *testing.T) {
+	var err error
+	var parser *Parser
+
+	parser = ArgumentParser("root", "help")
+	parser.AddOption("mode", "mode type").Short('m')
+	parser.AddOption("verbose", "verbose output").Short('v').Long("verbose").Bool(true).Local()
+
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("path", "upload path help").Short('p')
+	parser.Compile()
+
+	{
+		tmpParser, _ := parser.findParser(parser.Subs, []string{"upload"})
+		options := tmpParser.compile().options
+		assertEqualInt(t, 2, len(options))
+		if _, ok := options["verbose"]; ok {
+			t.Error("Expect the local option 'verbose' is not inherited")
+		}
+
+		err = tmpParser.bindParams(&Context{options: options}, []string{"-m", "test", "-p", "/home/admin"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = tmpParser.bindParams(&Context{options: options}, []string{"--verbose"})
+		if err == nil {
+			t.Fatal()
+		}
+		assertEqual(t, "Unrecognized arguments: --verbose", err.Error())
+
+		err = tmpParser.bindParams(&Context{options: options}, []string{"-v"})
+		if err == nil {
+			t.Fatal()
+		}
+		assertEqual(t, "Unknown short flag: 'v' in -v", err.Error())
+	}
+	{
+		tmpParser, _ := parser.findParser(parser.Subs, []string{})
+		options := tmpParser.compile().options
+		assertEqualInt(t, 2, len(options))
+
+		err = tmpParser.bindParams(&Context{options: options}, []string{"-v"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGlobalOptionDetailText(t *testing.T) {
+	parser := ArgumentParser("root", "help")
+	parser.AddOption("mode", "mode type").Short('m').Long("mode")
+	parser.AddOption("verbose", "verbose output").Long("verbose").Local()
+
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("path", "upload path help").Long("path")
+
+	check := upload.AddParser("check", "check help")
+	check.AddOption("mode", "check mode").Long("mode")
+
+	assertEqual(t, "      --path upload path help\n", upload.OptionDetailText())
+	assertEqual(t, "  -m, --mode mode type\n", upload.GlobalOptionDetailText())
+	// 覆盖了父Parser的dest
+	assertEqual(t, "      --path upload path help\n", check.GlobalOptionDetailText())
+	assertEqual(t, "", parser.GlobalOptionDetailText())
+}
+
+func TestParseArgsReuse(t *testing.T) {
+	parser := ArgumentParser("root", "help")
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	parser.AddOption("mode", "mode type").Short('m').Default("fast")
+
+	for i := 0; i < 3; i++ {
+		result := parser.ParseArgs([]string{})
+		if result.err != nil {
+			t.Fatal(result.err)
+		}
+		force, _ := result.cx.GetBool("force")
+		assertEqual(t, "false", fmt.Sprint(force))
+		mode, _ := result.cx.GetString("mode")
+		assertEqual(t, "fast", mode)
+	}
+
+	result := parser.ParseArgs([]string{"-f", "-m", "slow"})
+	force, _ := result.cx.GetBool("force")
+	assertEqual(t, "true", fmt.Sprint(force))
+	mode, _ := result.cx.GetString("mode")
+	assertEqual(t, "slow", mode)
+
+	// 不受上一次解析的影响
+	result = parser.ParseArgs([]string{})
+	force, _ = result.cx.GetBool("force")
+	assertEqual(t, "false", fmt.Sprint(force))
+	mode, _ = result.cx.GetString("mode")
+	assertEqual(t, "fast", mode)
+}
+
+func TestParseArgsConcurrent(t *testing.T) {
+	parser := ArgumentParser("root", "help")
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	parser.AddOption("tag", "tag of file").Long("tag")
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("path", "upload path help").Short('p').Required()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path := fmt.Sprintf("/home/%d", i)
+			args := []string{"upload", "-p", path, "--tag", path}
+			if i%2 == 0 {
+				args = append(args, "-f")
+			}
+			result := parser.ParseArgs(args)
+			if result.err != nil {
+				t.Error(result.err)
+				return
+			}
+			if v, _ := result.cx.GetString("path"); v != path {
+				t.Errorf("Expect %s, but got %s", path, v)
+			}
+			if v, _ := result.cx.GetString("tag"); v != path {
+				t.Errorf("Expect %s, but got %s", path, v)
+			}
+			if v, _ := result.cx.GetBool("force"); v != (i%2 == 0) {
+				t.Errorf("Expect force is %v, but got %v", i%2 == 0, v)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestSubCommandAliases(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	remove := parser.AddParser("remove", "remove files").Aliases("rm", "del")
+	remove.AddOption("force", "force remove").Short('f').Bool(true)
+	parser.AddParser("list", "list files")
+
+	for _, cmd := range []string{"remove", "rm", "del"} {
+		result := parser.ParseArgs([]string{cmd, "-f"})
+		if result.err != nil {
+			t.Fatal(result.err)
+		}
+		assertEqual(t, "remove", result.Title)
+		force, _ := result.cx.GetBool("force")
+		assertEqual(t, "true", fmt.Sprint(force))
+	}
+
+	assertEqual(t, "remove (rm, del) remove files\nlist             list files\n", parser.SubCommandText())
+
+	parser.AddParser("delete", "delete files").Aliases("del")
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting command 'del': 'delete' conflicts with 'remove' in root", err.Error())
+	}
+
+	// 先定义别名，再定义同名的子命令
+	parser = ArgumentParser("app", "help")
+	parser.AddParser("remove", "remove files").Aliases("rm")
+	parser.AddParser("rm", "rm files")
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting command 'rm': 'rm' conflicts with 'remove' in root", err.Error())
+	}
+	assertEqual(t, "remove (rm) remove files\n", parser.SubCommandText())
+}
+
+func TestHidden(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Short('m').Long("mode")
+	parser.AddOption("trace", "trace output").Long("trace").Bool(true).Hidden()
+	parser.AddParser("upload", "upload files")
+	debug := parser.AddParser("debug", "debug tools").Hidden()
+	debug.SetDefaults(func(c *Context) {})
+
+	// 隐藏的Option和子命令依然可以使用
+	result := parser.ParseArgs([]string{"debug", "--trace"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "debug", result.Title)
+
+	assertEqual(t, "upload upload files\n", parser.SubCommandText())
+	assertEqual(t, "  -m, --mode mode type\n", parser.OptionDetailText())
+	usage, _ := parser.Usage()
+	if strings.Contains(usage, "trace") || strings.Contains(usage, "debug") {
+		t.Error(usage)
+	}
+
+	usage, _ = parser.usage(true)
+	if !strings.Contains(usage, "--trace") || !strings.Contains(usage, "debug  debug tools") {
+		t.Error(usage)
+	}
+}
+
+func TestDispatchSubCommand(t *testing.T) {
+	called := []string{}
+	parser := ArgumentParser("app", "help")
+	parser.SetDefaults(func(c *Context) { called = append(called, "root") })
+	upload := parser.AddParser("upload", "upload help")
+	upload.SetDefaults(func(c *Context) { called = append(called, "upload") })
+	part := upload.AddParser("part", "part help")
+	part.SetDefaults(func(c *Context) { called = append(called, "part") })
+	// 不在命令路径上的必选Option不检查
+	parser.AddParser("download", "download help").AddOption("out", "file name").Required()
+
+	for _, args := range [][]string{{}, {"upload"}, {"upload", "part"}} {
+		result := parser.ParseArgs(args)
+		if err := result.Handle(); err != nil {
+			t.Fatal(args, err)
+		}
+	}
+	assertEqual(t, "root|upload|part", strings.Join(called, "|"))
+
+	// 命令路径上的必选Option依然检查
+	result := parser.ParseArgs([]string{"download"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Missing required option: '--out'", result.err.Error())
+
+	// 没有Handler的子命令
+	parser.AddParser("sync", "sync help")
+	result = parser.ParseArgs([]string{"sync"})
+	assertEqual(t, "missing handler in sync", result.Handle().Error())
+}
+
+func TestHandleError(t *testing.T) {
+	code := -1
+	osExit = func(c int) { code = c }
+	defer func() { osExit = os.Exit }()
+
+	parser := ArgumentParser("app", "help")
+	parser.SetDefaults(func(c *Context) {})
+	parser.AddParser("fail", "fail help").SetDefaults(func(c *Context) { c.Error(errors.New("upload failed")) })
+
+	out := captureStdout(t, func() { parser.ParseArgs([]string{}).HandleError() })
+	assertEqual(t, "", out)
+	assertEqualInt(t, -1, code)
+
+	out = captureStdout(t, func() { parser.ParseArgs([]string{"fail"}).HandleError() })
+	assertEqual(t, "err: upload failed\n", out)
+	assertEqualInt(t, 1, code)
+
+	out = captureStdout(t, func() { parser.ParseArgs([]string{"--unknown"}).HandleError() })
+	assertEqual(t, "err: Unrecognized arguments: --unknown\n", out)
+	assertEqualInt(t, 1, code)
+
+	code = -1
+	out = captureStdout(t, func() { parser.ParseArgs([]string{"-h"}).HandleError() })
+	if !strings.HasPrefix(out, "help\n") {
+		t.Error(out)
+	}
+	assertEqualInt(t, 0, code)
+}
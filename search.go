@@ -0,0 +1,142 @@
+package goargs
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// 内置的help子命令，用户定义了help子命令时不生效：
+//
+//	app help                  等同于 app -h
+//	app help upload part      等同于 app upload part -h
+//	app help --search file    在整个命令树中搜索命令、Option以及帮助信息，也可以使用 -k
+//	app help upload -k file   只搜索upload及其子命令
+//
+// --all 时包括隐藏的子命令和Option
+func (self *Parser) helpCommand(cmds []string, params []string) (string, error) {
+	keyword, all := "", false
+	for len(params) > 0 {
+		s := params[0]
+		params = params[1:]
+		switch {
+		case s == "--all":
+			all = true
+		case strings.HasPrefix(s, "--search="):
+			keyword = s[len("--search="):]
+		case strings.HasPrefix(s, "-k") && len(s) > 2:
+			keyword = s[2:]
+		case s == "--search" || s == "-k":
+			if len(params) == 0 {
+				return "", fmt.Errorf("Flag needs an argument: %s", s)
+			}
+			keyword, params = params[0], params[1:]
+		case len(s) > 0 && s[0] == '-':
+			return "", fmt.Errorf("Unrecognized arguments: %s", s)
+		default:
+			cmds = append(cmds, s)
+		}
+	}
+
+	parser, err := self.findParser(self.Subs, cmds)
+	if err != nil {
+		return "", err
+	}
+	if len(parser.path())-len(self.path()) != len(cmds) {
+		return "", ERR_NotFound
+	}
+	if keyword == "" {
+		return parser.usage(all)
+	}
+	return parser.searchText(keyword, all), nil
+}
+
+// 搜索的结果，比如 app upload --file 及其帮助信息
+type searchMatch struct {
+	name    string
+	snippet string
+}
+
+// 在当前命令及其子命令中搜索，不区分大小写；延迟构建的子命令会被构建
+func (self *Parser) search(keyword string, all bool) []searchMatch {
+	self.build()
+	keyword = strings.ToLower(keyword)
+	contains := func(items ...string) bool {
+		for _, v := range items {
+			if strings.Contains(strings.ToLower(v), keyword) {
+				return true
+			}
+		}
+		return false
+	}
+
+	out := []searchMatch{}
+	path := self.CommandPath()
+	if contains(append([]string{self.Name, self.summary(), self.description()}, self.aliases...)...) {
+		out = append(out, searchMatch{name: path, snippet: snippet(self.summary(), keyword)})
+	}
+	for _, v := range visibleOptions(self.orderedOptions(), all) {
+		if contains(append([]string{v.flags(), v.dest, v.help}, v.aliases...)...) {
+			out = append(out, searchMatch{name: path + " " + v.flags(), snippet: snippet(v.help, keyword)})
+		}
+	}
+	for _, v := range self.args {
+		if contains(v.dest, v.help) {
+			out = append(out, searchMatch{name: path + " " + v.argString(), snippet: snippet(v.help, keyword)})
+		}
+	}
+	for _, v := range self.orderedSubs() {
+		if all || !v.isHidden() {
+			out = append(out, v.search(keyword, all)...)
+		}
+	}
+	return out
+}
+
+func (self *Parser) searchText(keyword string, all bool) string {
+	matches := self.search(keyword, all)
+	if len(matches) == 0 {
+		return fmt.Sprintf("No matches for '%s'\n", keyword)
+	}
+	names := []string{}
+	snippets := []string{}
+	for _, v := range matches {
+		names = append(names, v.name)
+		snippets = append(snippets, v.snippet)
+	}
+	buf := new(bytes.Buffer)
+	for _, line := range alignRows(names, snippets, helpStyle{}.lineWidth()) {
+		fmt.Fprintln(buf, line)
+	}
+	return buf.String()
+}
+
+// 帮助信息的片段，合并为一行，过长时只保留关键字附近的内容
+func snippet(help string, keyword string) string {
+	const max = 60
+	runes := []rune(strings.Join(strings.Fields(help), " "))
+	if len(runes) <= max {
+		return string(runes)
+	}
+
+	start := 0
+	lower := strings.ToLower(string(runes))
+	if i := strings.Index(lower, keyword); i >= 0 {
+		start = len([]rune(lower[:i])) - max/3
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start > len(runes)-max {
+		start = len(runes) - max
+	}
+
+	s := string(runes[start : start+max])
+	if start > 0 {
+		s = "..." + s
+	}
+	if start+max < len(runes) {
+		s += "..."
+	}
+	return s
+}
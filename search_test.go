@@ -0,0 +1,145 @@
+package goargs
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHelpCommand(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddParser("upload", "upload files to the cloud").AddParser("part", "upload a part of the file")
+	parser.AddLazyParser("download", "download files", func(p *Parser) {})
+	parser.SetUsageTemplate("{{ .CommandPath }}")
+
+	for _, v := range []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{}, "app"},
+		{[]string{"upload"}, "app upload"},
+		{[]string{"upload", "part"}, "app upload part"},
+		{[]string{"download"}, "app download"},
+	} {
+		s, err := parser.helpCommand(v.args, nil)
+		if err != nil {
+			t.Fatal(v.args, err)
+		}
+		assertEqual(t, v.expect, s)
+	}
+
+	if _, err := parser.helpCommand([]string{"upload", "x"}, nil); err != ERR_NotFound {
+		t.Error(err)
+	}
+	if result := parser.ParseArgs([]string{"help", "upload"}); result.err != ERR_Usage {
+		t.Error(result.err)
+	}
+
+	// 用户定义的help子命令优先
+	parser.AddParser("help", "custom help")
+	if result := parser.ParseArgs([]string{"help"}); result.err != nil || result.Title != "help" {
+		t.Error(result.err)
+	}
+}
+
+func TestHelpSearch(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Long("mode")
+	upload := parser.AddParser("upload", "upload files to the cloud")
+	upload.AddOption("file", "remote file path").Long("file")
+	upload.AddParser("part", "upload a part of the file").AddOption("size", "part size").Long("size")
+	parser.AddLazyParser("download", "download files", func(p *Parser) {
+		p.AddOption("output", "save the file to the output path").Long("output")
+	})
+	parser.AddParser("debug", "debug tools").Hidden().AddOption("dump", "dump the file list").Long("dump")
+
+	s, err := parser.helpCommand(nil, []string{"--search", "FILE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, ""+
+		"app upload            upload files to the cloud\n"+
+		"app upload --file     remote file path\n"+
+		"app upload part       upload a part of the file\n"+
+		"app download          download files\n"+
+		"app download --output save the file to the output path\n", s)
+
+	// 包括隐藏的子命令
+	s, _ = parser.helpCommand(nil, []string{"-kdump", "--all"})
+	assertEqual(t, "app debug --dump dump the file list\n", s)
+
+	// 只搜索子命令
+	s, _ = parser.helpCommand([]string{"upload"}, []string{"-k", "size"})
+	assertEqual(t, "app upload part --size part size\n", s)
+
+	s, _ = parser.helpCommand(nil, []string{"--search=nothing"})
+	assertEqual(t, "No matches for 'nothing'\n", s)
+
+	if _, err = parser.helpCommand(nil, []string{"-k"}); err == nil {
+		t.Error()
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	assertEqual(t, "upload files to the cloud", snippet("upload  files\nto the cloud", "cloud"))
+	help := "The quick brown fox jumps over the lazy dog, and then the keyword appears here at the end of a very long line"
+	assertEqual(t, "...y dog, and then the keyword appears here at the end of a ver...", snippet(help, "keyword"))
+}
+
+// 捕获f输出到标准输出的内容
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	f()
+	w.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestHelpCommandSameAsFlag(t *testing.T) {
+	newParser := func() *Parser {
+		parser := ArgumentParser("app", "help")
+		parser.AddOption("mode", "testing mode").Short('m').Long("mode")
+		upload := parser.AddParser("upload", "upload files to the cloud")
+		upload.AddOption("file", "remote file path").Long("file")
+		upload.AddParser("part", "upload a part of the file").AddOption("size", "part size").Long("size")
+		parser.AddLazyParser("download", "download files", func(p *Parser) {
+			p.AddOption("output", "save the file to the output path").Long("output")
+		})
+		parser.AddParser("sync", "sync files").AddOption("out", "file name").Required()
+		return parser
+	}
+
+	for _, cmd := range [][]string{{}, {"upload"}, {"upload", "part"}, {"download"}, {"sync"}} {
+		// 每次使用新的Parser，避免 -h 先编译
+		byCommand := captureStdout(t, func() {
+			parser := newParser()
+			if result := parser.ParseArgs(append([]string{"help"}, cmd...)); result.err != ERR_Usage {
+				t.Error(result.err)
+			}
+		})
+		byFlag := captureStdout(t, func() {
+			parser := newParser()
+			if result := parser.ParseArgs(append(cmd, "-h")); result.err != ERR_Usage {
+				t.Error(result.err)
+			}
+		})
+		assertEqual(t, byFlag, byCommand)
+	}
+
+	parser := newParser()
+	s, _ := parser.helpCommand([]string{"sync"}, nil)
+	if !strings.Contains(s, "\n      --out file name\n") {
+		t.Error(s)
+	}
+}
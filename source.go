@@ -0,0 +1,37 @@
+package goargs
+
+import (
+	"fmt"
+)
+
+// Option值的来源
+type SourceKind int
+
+const (
+	Unset SourceKind = iota
+	Default
+	ConfigFile
+	Env
+	CommandLine
+)
+
+type Source struct {
+	Kind SourceKind
+	Name string // 环境变量名，Kind为Env时有效
+	Path string // 配置文件路径，Kind为ConfigFile时有效
+	Line int    // 配置文件行号，Kind为ConfigFile时有效
+}
+
+func (self Source) String() string {
+	switch self.Kind {
+	case Default:
+		return "default"
+	case ConfigFile:
+		return fmt.Sprintf("config %s:%d", self.Path, self.Line)
+	case Env:
+		return fmt.Sprintf("env %s", self.Name)
+	case CommandLine:
+		return "command line"
+	}
+	return "unset"
+}
@@ -0,0 +1,82 @@
+package goargs
+
+import (
+	"os"
+	"testing"
+)
+
+func TestContextSource(t *testing.T) {
+	path := writeConfig(t, "app.ini", "\n[upload]\nfile = readme.md\n")
+
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Short('m').Default("test")
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	parser.AddParser("upload", "upload help").AddOption("file", "file name").Required()
+	parser.AddOption("region", "region").Env("GOARGS_TEST_REGION")
+	parser.AddOption("profile", "profile")
+	parser.SetConfigFile(path)
+	os.Setenv("GOARGS_TEST_REGION", "cn")
+	defer os.Unsetenv("GOARGS_TEST_REGION")
+
+	result := parser.ParseArgs([]string{"upload", "-f"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	cx := result.cx
+
+	assertEqual(t, "command line", cx.Source("force").String())
+	assertEqual(t, "env GOARGS_TEST_REGION", cx.Source("region").String())
+	assertEqual(t, "config "+path+":3", cx.Source("file").String())
+	assertEqual(t, "default", cx.Source("mode").String())
+	assertEqual(t, "unset", cx.Source("profile").String())
+	assertEqual(t, "unset", cx.Source("missing").String())
+
+	if cx.Source("file").Kind != ConfigFile || cx.Source("file").Line != 3 {
+		t.Error(cx.Source("file"))
+	}
+
+	if !cx.IsSet("force") || !cx.IsSet("region") || !cx.IsSet("file") {
+		t.Error("Expect force, region and file are set")
+	}
+	if cx.IsSet("mode") || cx.IsSet("profile") || cx.IsSet("missing") {
+		t.Error("Expect mode, profile and missing are not set")
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type").Short('m').Default("test")
+	parser.AddOption("config", "config file").Short('c').ConfigPath()
+	parser.AddOption("force", "force do something").Short('f').Bool(true)
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("file", "file name").Required()
+	result := parser.ParseArgs([]string{"upload", "--print-config", "--file", "readme.md"})
+	if result.err != ERR_Usage {
+		t.Fatal(result.err)
+	}
+
+	expect := "config =  (unset)\n" +
+		"file   = readme.md (command line)\n" +
+		"force  = false (default)\n" +
+		"mode   = test (default)\n"
+	assertEqual(t, expect, result.cx.explain())
+}
+
+func TestPrintConfigMissingRequired(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "testing mode").Short('m').Default("test")
+	parser.AddOption("region", "region").Long("region").Required()
+
+	// 缺少必选参数时依然输出参数的来源
+	result := parser.ParseArgs([]string{"--print-config"})
+	if result.err != ERR_Usage {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "mode   = test (default)\nregion =  (unset)\n", result.cx.explain())
+
+	result = parser.ParseArgs([]string{})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Missing required option: '--region'", result.err.Error())
+}
@@ -0,0 +1,239 @@
+package goargs
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 结构体实现Runner时，Run方法作为对应命令的Handler
+type Runner interface {
+	Run(c *Context) error
+}
+
+// 根据结构体的定义创建Parser，v必须为结构体指针
+//
+//	type Upload struct {
+//		File string `goargs:"-f,--file,required" help:"file name"`
+//	}
+//	type App struct {
+//		Mode   string `goargs:"-m,--mode" default:"test" env:"APP_MODE" help:"testing mode"`
+//		Upload Upload `goargs:"command" help:"upload file to cloud"`
+//	}
+//
+// goargs标签支持：
+//   - -x, --name：设置Short和Long
+//   - required：必选参数
+//   - local：只在所属的命令生效，不被子命令继承
+//   - hidden：不在帮助信息中显示
+//   - secret：敏感信息，不显示参数值
+//   - dest=name：设置dest，默认为字段名的kebab-case
+//   - command, command=name：字段为子命令，默认名称为字段名的kebab-case
+//   - "-"：忽略该字段
+//
+// 另外，help、default、env标签设置帮助信息、默认值和环境变量，choices标签设置可选的参数值（逗号分隔），
+// group标签设置Option的分组，category标签设置子命令的分类
+//
+// 解析完成后，参数值会写回结构体的字段
+func StructParser(n string, h string, v interface{}) (*Parser, error) {
+	parser := ArgumentParser(n, h)
+	if err := parser.AddStruct(v); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
+
+// 将结构体的字段添加为当前Parser的Option和子命令
+func (self *Parser) AddStruct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expect a pointer to struct but %T", v)
+	}
+
+	if err := self.addStructFields(rv.Elem()); err != nil {
+		return err
+	}
+
+	if r, ok := v.(Runner); ok {
+		self.SetDefaults(func(c *Context) {
+			if err := r.Run(c); err != nil {
+				c.Error(err)
+			}
+		})
+	}
+	return nil
+}
+
+func (self *Parser) addStructFields(rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, hasTag := field.Tag.Lookup("goargs")
+		if tag == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		fv := rv.Field(i)
+		items := []string{}
+		if hasTag && tag != "" {
+			items = strings.Split(tag, ",")
+		}
+
+		// 匿名的结构体，展开其字段
+		if field.Anonymous && !hasTag && field.Type.Kind() == reflect.Struct {
+			if err := self.addStructFields(fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		isCommand := false
+		name := kebabCase(field.Name)
+		for _, item := range items {
+			if item == "command" {
+				isCommand = true
+			} else if strings.HasPrefix(item, "command=") {
+				isCommand = true
+				name = item[len("command="):]
+			}
+		}
+
+		if isCommand {
+			if err := self.addStructCommand(name, field, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := self.addStructOption(name, items, field, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *Parser) addStructCommand(name string, field reflect.StructField, fv reflect.Value) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.Type().Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("command field '%s' must be a struct", field.Name)
+		}
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+	} else if fv.Kind() == reflect.Struct {
+		fv = fv.Addr()
+	} else {
+		return fmt.Errorf("command field '%s' must be a struct", field.Name)
+	}
+
+	sub := self.AddParser(name, field.Tag.Get("help")).Category(field.Tag.Get("category"))
+	return sub.AddStruct(fv.Interface())
+}
+
+func (self *Parser) addStructOption(dest string, items []string, field reflect.StructField, fv reflect.Value) error {
+	for _, item := range items {
+		if strings.HasPrefix(item, "dest=") {
+			dest = item[len("dest="):]
+		}
+	}
+
+	opt := self.AddOption(dest, field.Tag.Get("help"))
+	for _, item := range items {
+		switch {
+		case strings.HasPrefix(item, "--"):
+			opt.Long(item[2:])
+		case strings.HasPrefix(item, "-"):
+			r := []rune(item[1:])
+			if len(r) != 1 {
+				return fmt.Errorf("bad short option '%s' in field '%s'", item, field.Name)
+			}
+			opt.Short(r[0])
+		case item == "required":
+			opt.Required()
+		case item == "local":
+			opt.Local()
+		case item == "hidden":
+			opt.Hidden()
+		case item == "secret":
+			opt.Secret()
+		case strings.HasPrefix(item, "dest="):
+		default:
+			return fmt.Errorf("unknown tag '%s' in field '%s'", item, field.Name)
+		}
+	}
+
+	if env, ok := field.Tag.Lookup("env"); ok {
+		opt.Env(env)
+	}
+	if choices, ok := field.Tag.Lookup("choices"); ok {
+		opt.Choices(strings.Split(choices, ",")...)
+	}
+	if group, ok := field.Tag.Lookup("group"); ok {
+		opt.Group(self.AddGroup(group, ""))
+	}
+
+	// 默认值优先使用default标签，其次为字段当前的非零值
+	def, hasDef := field.Tag.Lookup("default")
+	if !hasDef && !fv.IsZero() {
+		def, hasDef = structFieldString(fv), true
+	}
+
+	switch p := fv.Addr().Interface().(type) {
+	case *string:
+		opt.StringVar(p)
+	case *int:
+		opt.IntVar(p)
+	case *time.Duration:
+		opt.DurationVar(p)
+	case *[]string:
+		opt.StringSliceVar(p)
+	case *bool:
+		// 默认为true时，出现即为false
+		if hasDef {
+			b, err := strconv.ParseBool(def)
+			if err != nil {
+				return fmt.Errorf("bad default '%s' in field '%s'", def, field.Name)
+			}
+			opt.Bool(!b)
+			hasDef = false
+		}
+		opt.BoolVar(p)
+	default:
+		return fmt.Errorf("unsupported type %s of field '%s'", field.Type, field.Name)
+	}
+
+	if hasDef {
+		opt.Default(def)
+	}
+	return nil
+}
+
+func structFieldString(fv reflect.Value) string {
+	switch v := fv.Interface().(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case time.Duration:
+		return v.String()
+	}
+	return fmt.Sprint(fv.Interface())
+}
+
+// FileName -> file-name, URLPath -> url-path
+func kebabCase(s string) string {
+	runes := []rune(s)
+	out := []rune{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				out = append(out, '-')
+			}
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
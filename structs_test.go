@@ -0,0 +1,172 @@
+package goargs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testUploadCmd struct {
+	File    string        `goargs:"-f,--file,required" help:"file name"`
+	Tags    []string      `goargs:"-t" help:"tags"`
+	Timeout time.Duration `default:"3s" help:"timeout"`
+	ran     bool
+}
+
+func (self *testUploadCmd) Run(c *Context) error {
+	self.ran = true
+	if self.File == "bad" {
+		return errors.New("bad file")
+	}
+	return nil
+}
+
+type testCommonOpts struct {
+	Region string `goargs:"--region" default:"cn" help:"region"`
+}
+
+type testApp struct {
+	testCommonOpts
+	Mode     string         `goargs:"-m,--mode" default:"test" help:"testing mode"`
+	Retry    int            `goargs:"dest=retries" help:"retry count"`
+	Force    bool           `goargs:"-F" help:"force do something"`
+	Check    bool           `default:"true" help:"check after upload"`
+	Upload   testUploadCmd  `goargs:"command" help:"upload file to cloud"`
+	Download *testUploadCmd `goargs:"command=get" help:"download file"`
+	Ignored  string         `goargs:"-"`
+}
+
+func TestStructParser(t *testing.T) {
+	app := &testApp{Retry: 3}
+	parser, err := StructParser("app", "help", app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := parser.Opts["ignored"]; ok {
+		t.Error("Expect field 'Ignored' is skipped")
+	}
+	if _, ok := parser.Subs["get"]; !ok {
+		t.Error("Expect the command 'get'")
+	}
+	assertEqual(t, "upload file to cloud", parser.Subs["upload"].Help)
+
+	result := parser.ParseArgs([]string{"upload", "-m", "debug", "-F", "--retries=5", "--file", "a.txt", "-t", "x,y", "--check"})
+	if err = result.Handle(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, "debug", app.Mode)
+	assertEqual(t, "cn", app.Region)
+	assertEqualInt(t, 5, app.Retry)
+	if !app.Force || app.Check {
+		t.Error(app.Force, app.Check)
+	}
+	assertEqual(t, "a.txt", app.Upload.File)
+	assertEqual(t, "x,y", strings.Join(app.Upload.Tags, ","))
+	if app.Upload.Timeout != 3*time.Second {
+		t.Error(app.Upload.Timeout)
+	}
+	if !app.Upload.ran {
+		t.Error("Expect the handler of upload is called")
+	}
+	if app.Download == nil || app.Download.ran {
+		t.Error("Expect the handler of get is not called")
+	}
+}
+
+func TestStructParserRunError(t *testing.T) {
+	app := &testApp{}
+	parser, err := StructParser("app", "help", app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := parser.ParseArgs([]string{"get", "--file", "bad"})
+	err = result.Handle()
+	if err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "bad file", err.Error())
+
+	parser, _ = StructParser("app", "help", &testApp{})
+	result = parser.ParseArgs([]string{"upload"})
+	if err = result.Handle(); err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Missing required option: '-f/--file'", err.Error())
+}
+
+func TestStructParserInvalid(t *testing.T) {
+	if _, err := StructParser("app", "help", testApp{}); err == nil {
+		t.Error("Expect error for non-pointer")
+	}
+
+	var badType struct {
+		Ratio float64
+	}
+	_, err := StructParser("app", "help", &badType)
+	if err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "unsupported type float64 of field 'Ratio'", err.Error())
+
+	var badTag struct {
+		Mode string `goargs:"-mode"`
+	}
+	_, err = StructParser("app", "help", &badTag)
+	if err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "bad short option '-mode' in field 'Mode'", err.Error())
+}
+
+func TestKebabCase(t *testing.T) {
+	assertEqual(t, "file", kebabCase("File"))
+	assertEqual(t, "file-name", kebabCase("FileName"))
+	assertEqual(t, "url-path", kebabCase("URLPath"))
+	assertEqual(t, "id", kebabCase("ID"))
+}
+
+func TestStructGroupAndCategory(t *testing.T) {
+	type Upload struct {
+		Host string `goargs:"--host" group:"Network" help:"server host"`
+	}
+	type App struct {
+		Mode   string `goargs:"-m,--mode" help:"testing mode"`
+		Upload Upload `goargs:"command" category:"Transfer Commands" help:"upload file to cloud"`
+	}
+	parser, err := StructParser("app", "help", &App{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "Transfer Commands:\nupload upload file to cloud\n", parser.SubCommandText())
+	assertEqual(t, "Network:\n      --host server host\n", parser.Subs["upload"].OptionDetailText())
+}
+
+func TestStructChoicesAndSecret(t *testing.T) {
+	type App struct {
+		Mode     string `goargs:"-m,--mode" choices:"test,prod" default:"test" help:"testing mode"`
+		Password string `goargs:"--password,secret" default:"123456" help:"login password"`
+	}
+	app := &App{}
+	parser, err := StructParser("app", "help", app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.SetHelpFormatter(&TemplateFormatter{Template: "{{ .OptionDetailText }}", ShowDefaults: true, ShowChoices: true})
+	usage, _ := parser.Usage()
+	assertEqual(t, ""+
+		"  -m, --mode     testing mode (default: test) {test,prod}\n"+
+		"      --password login password (default: ******)\n", usage)
+
+	if result := parser.ParseArgs([]string{"-m", "dev"}); result.err == nil {
+		t.Fatal()
+	}
+	if result := parser.ParseArgs([]string{"-m", "prod"}); result.err != nil {
+		t.Fatal(result.err)
+	}
+	assertEqual(t, "prod", app.Mode)
+	assertEqual(t, "123456", app.Password)
+}
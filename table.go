@@ -0,0 +1,174 @@
+package goargs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// 编译后的查找表，包含当前命令可见的所有Option（包括继承的Option）
+// 解析时只查表，不再回溯父Parser，也不再遍历整个命令树
+type lookupTable struct {
+	gen     int64              // 编译时的定义版本，定义改变后重新编译
+	long    map[string]*Option // long、别名 - option
+	short   map[rune]*Option   // short - option
+	options map[string]*Option // dest - 保存值的option
+	names   []string           // 排序后的long，用于缩写查找
+}
+
+// 编译整个命令树的查找表，返回定义时发现的错误
+// 可以在启动时调用，避免第一次解析时编译；之后修改定义会使查找表失效
+func (self *Parser) Compile() error {
+	root := self.root()
+	root.mu.Lock()
+	parsers := self.walk(nil)
+	for _, p := range parsers {
+		p.preFilterOptions()
+	}
+	for _, p := range parsers {
+		p.table = p.buildTable()
+	}
+	root.mu.Unlock()
+	return self.Err()
+}
+
+// 设置为true时，允许使用long的唯一前缀，比如 --verb 等同于 --verbose
+func (self *Parser) SetAllowAbbrev(allow bool) {
+	self.root().allowAbbrev = allow
+}
+
+// 定义发生变化，已经编译的查找表失效
+func (self *Parser) changed() {
+	if self != nil {
+		atomic.AddInt64(&self.root().gen, 1)
+	}
+}
+
+// 当前的定义版本
+func (self *Parser) generation() int64 {
+	return atomic.LoadInt64(&self.root().gen)
+}
+
+// 遍历命令树，跳过未完成构建的子命令；需要持有root.mu
+func (self *Parser) walk(out []*Parser) []*Parser {
+	out = append(out, self)
+	for _, v := range self.Subs {
+		if v.pending() {
+			continue
+		}
+		out = v.walk(out)
+	}
+	return out
+}
+
+// 从根到当前Parser的命令路径
+func (self *Parser) path() []*Parser {
+	out := []*Parser{}
+	for p := self; p != nil; p = p.Super {
+		out = append([]*Parser{p}, out...)
+	}
+	return out
+}
+
+// 获取当前Parser的查找表，未编译或已失效时只编译当前命令路径
+func (self *Parser) compile() *lookupTable {
+	root := self.root()
+	root.mu.RLock()
+	table := self.table
+	valid := table != nil && table.gen == root.generation()
+	root.mu.RUnlock()
+	if valid {
+		return table
+	}
+
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if self.table == nil || self.table.gen != root.generation() {
+		for _, p := range self.path() {
+			p.preFilterOptions()
+		}
+		self.table = self.buildTable()
+	}
+	return self.table
+}
+
+// 设置当前Parser的默认longV等，不处理子命令
+func (self *Parser) preFilterOptions() {
+	for _, v := range self.Opts {
+		v.pre()
+	}
+}
+
+func (self *Parser) buildTable() *lookupTable {
+	table := &lookupTable{
+		gen:     self.generation(),
+		long:    map[string]*Option{},
+		short:   map[rune]*Option{},
+		options: map[string]*Option{},
+	}
+
+	// 从根开始，子命令的Option覆盖父命令的Option，父命令的本地Option不可见
+	for _, p := range self.path() {
+		visible := func(o *Option) bool { return p == self || !o.local }
+		for k, v := range p.LongOpts {
+			if visible(v) {
+				table.long[k] = v
+			}
+		}
+		for k, v := range p.ShortOpts {
+			if visible(v) {
+				table.short[k] = v
+			}
+		}
+		// 多个Option共享dest时，由名称与dest相同的Option保存值，没有时由先定义的Option保存值
+		held := map[string]bool{}
+		for _, k := range p.optNames {
+			v, ok := p.Opts[k]
+			if !ok || !visible(v) || held[v.dest] {
+				continue
+			}
+			if _, ok := p.Opts[v.dest]; ok && k != v.dest {
+				continue
+			}
+			held[v.dest] = true
+			table.options[v.dest] = v
+		}
+	}
+	// 位置参数只属于当前命令
+	for _, v := range self.args {
+		table.options[v.dest] = v
+	}
+
+	for k := range table.long {
+		table.names = append(table.names, k)
+	}
+	sort.Strings(table.names)
+	return table
+}
+
+// 查找long，允许缩写时，唯一前缀匹配的Option也会返回
+func (self *lookupTable) longOption(name string, abbrev bool) (*Option, error) {
+	if v, ok := self.long[name]; ok {
+		return v, nil
+	}
+	if !abbrev {
+		return nil, nil
+	}
+
+	var found *Option
+	matches := []string{}
+	ambiguous := false
+	for i := sort.SearchStrings(self.names, name); i < len(self.names) && strings.HasPrefix(self.names[i], name); i++ {
+		v := self.long[self.names[i]]
+		matches = append(matches, "--"+self.names[i])
+		if found != nil && found != v {
+			ambiguous = true
+		}
+		found = v
+	}
+	if ambiguous {
+		return nil, fmt.Errorf("Ambiguous option: --%s could match %s", name, strings.Join(matches, ", "))
+	}
+	return found, nil
+}
@@ -0,0 +1,153 @@
+package goargs
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAbbrev(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("verbose", "verbose output").Long("verbose").Bool(true)
+	parser.AddOption("version", "show version").Long("version").Bool(true)
+	parser.AddOption("mode", "mode type").Long("mode")
+
+	// 默认不允许缩写
+	result := parser.ParseArgs([]string{"--mo", "fast"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Unrecognized arguments: --mo", result.err.Error())
+
+	parser.SetAllowAbbrev(true)
+	result = parser.ParseArgs([]string{"--mo", "fast", "--verb"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	mode, _ := result.cx.GetString("mode")
+	assertEqual(t, "fast", mode)
+	verbose, _ := result.cx.GetBool("verbose")
+	assertEqual(t, "true", fmt.Sprint(verbose))
+
+	result = parser.ParseArgs([]string{"--ver"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Ambiguous option: --ver could match --verbose, --version", result.err.Error())
+}
+
+func TestAliases(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.SetAllowAbbrev(true)
+	parser.AddOption("output", "output file").Long("output").Aliases("out", "output-file")
+
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("path", "upload path").Short('p')
+
+	for _, args := range [][]string{
+		{"upload", "--output", "a.txt"},
+		{"upload", "--out", "a.txt"},
+		{"upload", "--output-file=a.txt"},
+		// 别名与long属于同一个Option，不视为歧义
+		{"upload", "--outp", "a.txt"},
+	} {
+		result := parser.ParseArgs(args)
+		if result.err != nil {
+			t.Fatal(args, result.err)
+		}
+		output, _ := result.cx.GetString("output")
+		assertEqual(t, "a.txt", output)
+	}
+
+	parser.AddOption("outline", "outline mode").Long("outline").Bool(true)
+	if result := parser.ParseArgs([]string{"--outl"}); result.err != nil {
+		t.Fatal(result.err)
+	}
+	if err := parser.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	parser.AddOption("format", "output format").Aliases("out")
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Conflicting option --out: 'format' conflicts with 'output' in root", err.Error())
+	}
+}
+
+func TestCompile(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("mode", "mode type")
+	upload := parser.AddParser("upload", "upload help")
+	upload.AddOption("path", "upload path").Short('p')
+	if err := parser.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 编译时设置默认的long
+	assertEqual(t, "mode", parser.Opts["mode"].longV)
+	if _, ok := upload.table.long["mode"]; !ok {
+		t.Error("Expect the inherited option is in the table")
+	}
+
+	result := parser.ParseArgs([]string{"upload", "--mode", "fast", "-p", "/tmp"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+
+	// 修改定义后重新编译
+	upload.AddOption("force", "force upload").Short('f').Bool(true)
+	result = parser.ParseArgs([]string{"upload", "-f"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	force, _ := result.cx.GetBool("force")
+	assertEqual(t, "true", fmt.Sprint(force))
+
+	parser.AddOption("mode", "mode type")
+	if err := parser.Compile(); err == nil {
+		t.Fatal()
+	}
+}
+
+// 构建一个命令树：n个子命令，每个子命令m个Option
+func genLargeParser(n int, m int) *Parser {
+	parser := ArgumentParser("app", "help")
+	parser.AddOption("verbose", "verbose output").Short('v').Bool(true)
+	for i := 0; i < n; i++ {
+		sub := parser.AddParser(fmt.Sprintf("cmd%d", i), "command help")
+		for j := 0; j < m; j++ {
+			sub.AddOption(fmt.Sprintf("opt%d", j), "option help")
+		}
+		sub.SetDefaults(func(c *Context) {})
+	}
+	return parser
+}
+
+func benchmarkParseArgs(b *testing.B, n int) {
+	parser := genLargeParser(n, 10)
+	if err := parser.Compile(); err != nil {
+		b.Fatal(err)
+	}
+	args := []string{"cmd0", "-v", "--opt3", "value", "--opt7=value"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if result := parser.ParseArgs(args); result.err != nil {
+			b.Fatal(result.err)
+		}
+	}
+}
+
+// 解析的耗时与命令树的大小无关
+func BenchmarkParseArgs10(b *testing.B)   { benchmarkParseArgs(b, 10) }
+func BenchmarkParseArgs100(b *testing.B)  { benchmarkParseArgs(b, 100) }
+func BenchmarkParseArgs1000(b *testing.B) { benchmarkParseArgs(b, 1000) }
+
+func BenchmarkCompile1000(b *testing.B) {
+	parser := genLargeParser(1000, 10)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parser.changed()
+		parser.Compile()
+	}
+}
@@ -0,0 +1,141 @@
+package goargs
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 类型 - 解析函数
+var (
+	typeParsers   = map[reflect.Type]decodeFunc{}
+	typeParsersMu sync.RWMutex
+)
+
+// 注册类型T的解析函数，注册之后即可通过Add[T]创建该类型的Option
+// 可以与解析并发调用，通常在init中注册
+func RegisterType[T any](parse func(s string) (T, error)) {
+	typeParsersMu.Lock()
+	defer typeParsersMu.Unlock()
+	typeParsers[typeOf[T]()] = func(s string) (interface{}, error) {
+		return parse(s)
+	}
+}
+
+func lookupTypeParser(t reflect.Type) (decodeFunc, bool) {
+	typeParsersMu.RLock()
+	defer typeParsersMu.RUnlock()
+	parse, ok := typeParsers[t]
+	return parse, ok
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func init() {
+	RegisterType(func(s string) (string, error) { return s, nil })
+	RegisterType(strconv.Atoi)
+	RegisterType(func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) })
+	RegisterType(func(s string) (uint, error) {
+		v, err := strconv.ParseUint(s, 10, 0)
+		return uint(v), err
+	})
+	RegisterType(func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })
+	RegisterType(strconv.ParseBool)
+	RegisterType(time.ParseDuration)
+}
+
+// 带类型的Option，比如：
+//
+//	mode := goargs.Add[string](root, "mode", "testing mode").Short('m').Default("test")
+//	...
+//	mode.Get(c)
+type Opt[T any] struct {
+	option *Option
+}
+
+// 添加类型为T的Option，bool类型为出现即为true的Flag，[]string类型的Option可以重复设置
+// 类型未注册时记录错误，通过Err、Validate或ParseArgs返回
+func Add[T any](p *Parser, dest string, help string) *Opt[T] {
+	option := p.AddOption(dest, help)
+	option.valueType = typeOf[T]()
+
+	switch typeOf[T]() {
+	case typeOf[bool]():
+		option.Bool(true)
+	case typeOf[[]string]():
+		option.multi = true
+	default:
+		parse, ok := lookupTypeParser(typeOf[T]())
+		if !ok {
+			// 与Option冲突一样记录错误，ParseArgs时返回
+			p.defError("No parser registered for type %s: '%s' in %s", typeOf[T](), dest, p.Title)
+			break
+		}
+		option.decoder = parse
+	}
+	return &Opt[T]{option: option}
+}
+
+func (self *Opt[T]) Short(s rune) *Opt[T] {
+	self.option.Short(s)
+	return self
+}
+
+func (self *Opt[T]) Long(l string) *Opt[T] {
+	self.option.Long(l)
+	return self
+}
+
+func (self *Opt[T]) Required() *Opt[T] {
+	self.option.Required()
+	return self
+}
+
+func (self *Opt[T]) Env(name string) *Opt[T] {
+	self.option.Env(name)
+	return self
+}
+
+// bool类型的默认值为true时，出现即为false
+func (self *Opt[T]) Default(v T) *Opt[T] {
+	if b, ok := interface{}(v).(bool); ok {
+		self.option.Bool(!b)
+		return self
+	}
+	self.option.Default(v)
+	return self
+}
+
+// 获取底层的Option
+func (self *Opt[T]) Option() *Option {
+	return self.option
+}
+
+// 获取参数值，出错时为零值，需要错误信息时使用Value
+func (self *Opt[T]) Get(c *Context) T {
+	v, _ := self.Value(c)
+	return v
+}
+
+// 获取参数值，未设置且没有默认值时为零值
+// 多个Option共享dest，或者Action设置了其他类型的值时返回错误
+func (self *Opt[T]) Value(c *Context) (T, error) {
+	var zero T
+	o, ok := c.lookup(self.option.dest)
+	if !ok {
+		return zero, ERR_NotFound
+	}
+	v, err := o.decode()
+	if err != nil || v == nil {
+		return zero, err
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("Option '%s' holds a value of type %T, not %s", self.option.getOptString(), v, typeOf[T]())
+	}
+	return t, nil
+}
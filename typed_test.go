@@ -0,0 +1,125 @@
+package goargs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testLevel int
+
+func TestTypedOption(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	mode := Add[string](parser, "mode", "testing mode").Short('m').Default("test")
+	count := Add[int](parser, "count", "count").Short('n').Default(5)
+	ratio := Add[float64](parser, "ratio", "ratio").Long("ratio")
+	force := Add[bool](parser, "force", "force do something").Short('f')
+	check := Add[bool](parser, "check", "check after upload").Long("check").Default(true)
+	timeout := Add[time.Duration](parser, "timeout", "timeout").Long("timeout").Default(time.Second)
+	tags := Add[[]string](parser, "tag", "tags").Short('t')
+
+	result := parser.ParseArgs([]string{"-n", "7", "--ratio=0.5", "-f", "--check", "-t", "a", "-t", "b"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	cx := result.cx
+
+	assertEqual(t, "test", mode.Get(cx))
+	assertEqualInt(t, 7, count.Get(cx))
+	if ratio.Get(cx) != 0.5 {
+		t.Error(ratio.Get(cx))
+	}
+	if !force.Get(cx) || check.Get(cx) {
+		t.Error(force.Get(cx), check.Get(cx))
+	}
+	if timeout.Get(cx) != time.Second {
+		t.Error(timeout.Get(cx))
+	}
+	assertEqual(t, "a,b", strings.Join(tags.Get(cx), ","))
+
+	// 默认值不是字符串时，Context的方法依然可用
+	if v, err := cx.GetString("timeout"); err != nil || v != "1s" {
+		t.Error(v, err)
+	}
+}
+
+func TestTypedOptionInvalid(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	Add[int](parser, "count", "count").Short('n')
+
+	result := parser.ParseArgs([]string{"-n", "x"})
+	if result.err == nil {
+		t.Fatal()
+	}
+	assertEqual(t, "Invalid value 'x' for option: '-n'", result.err.Error())
+}
+
+func TestTypedOptionRegister(t *testing.T) {
+	RegisterType(func(s string) (testLevel, error) {
+		switch s {
+		case "low":
+			return 1, nil
+		case "high":
+			return 2, nil
+		}
+		return 0, fmt.Errorf("bad level %s", s)
+	})
+
+	parser := ArgumentParser("app", "help")
+	level := Add[testLevel](parser, "level", "level").Short('l')
+
+	result := parser.ParseArgs([]string{"-l", "high"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	if level.Get(result.cx) != 2 {
+		t.Error(level.Get(result.cx))
+	}
+
+	// 未注册的类型记录为定义错误
+	Add[complex64](parser, "complex", "complex")
+	if err := parser.Err(); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "No parser registered for type complex64: 'complex' in root", err.Error())
+	}
+	if result := parser.ParseArgs([]string{"-l", "high"}); result.err == nil {
+		t.Fatal()
+	}
+}
+
+func TestTypedOptionValue(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	count := Add[int](parser, "count", "count").Short('n')
+	// Action设置了其他类型的值
+	count.Option().StoreConst(0.5)
+
+	result := parser.ParseArgs([]string{"-n"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	if _, err := count.Value(result.cx); err == nil {
+		t.Fatal()
+	} else {
+		assertEqual(t, "Option '-n' holds a value of type float64, not int", err.Error())
+	}
+	assertEqualInt(t, 0, count.Get(result.cx))
+}
+
+func TestTypedOptionRegisterConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterType(func(s string) (testLevel, error) { return 1, nil })
+		}()
+		go func() {
+			defer wg.Done()
+			Add[int](ArgumentParser("app", "help"), "count", "count")
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,151 @@
+package goargs
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (self Severity) String() string {
+	if self == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Validate发现的问题
+type Finding struct {
+	Severity Severity
+	Location string // 命令路径以及Option，比如 app upload --file
+	Message  string
+}
+
+func (self Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", self.Severity, self.Location, self.Message)
+}
+
+var kebabCasePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// 检查整个命令树的定义，可以在单元测试中调用，比如：
+//
+//	for _, f := range root.Validate() {
+//		t.Error(f)
+//	}
+//
+// 与ParseArgs一样，先构建延迟构建的子命令并设置默认的long，再检查冲突
+func (self *Parser) Validate() []Finding {
+	self.walkAll(nil)
+	findings := []Finding{}
+	path := self.CommandPath()
+	for _, f := range self.definitionErrors() {
+		if self == self.root() || f.Location == path || strings.HasPrefix(f.Location, path+" ") {
+			findings = append(findings, f)
+		}
+	}
+	return append(findings, self.validate()...)
+}
+
+func (self *Parser) validate() []Finding {
+	self.build()
+	findings := []Finding{}
+	path := self.CommandPath()
+	add := func(severity Severity, location string, format string, a ...interface{}) {
+		findings = append(findings, Finding{Severity: severity, Location: location, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if self.Super != nil && self.summary() == "" {
+		add(SeverityWarning, path, "empty help")
+	}
+	if len(self.Subs) == 0 && self.HandlerFunc == nil {
+		add(SeverityError, path, "missing handler")
+	}
+
+	names := []string{}
+	for k := range self.Opts {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		v := self.Opts[k]
+		location := path + " " + v.getOptString()
+
+		if v.help == "" {
+			add(SeverityWarning, location, "empty help")
+		}
+		if v.requiredV && v.defValue != nil {
+			add(SeverityWarning, location, "required option has a default value")
+		}
+		if err := v.checkDefault(); err != nil {
+			add(SeverityError, location, "%s", err)
+		}
+
+		long := v.longV
+		if long == "" && v.shortV == 0 {
+			long = v.name
+		}
+		if long != "" && !kebabCasePattern.MatchString(long) {
+			add(SeverityWarning, location, "long option '--%s' is not kebab-case", long)
+		}
+
+		for p := self.Super; p != nil; p = p.Super {
+			if o := p.destOption(v.dest); o != nil && !o.local {
+				add(SeverityWarning, location, "dest '%s' collides with the inherited option '%s' in %s", v.dest, o.getOptString(), p.CommandPath())
+				break
+			}
+		}
+	}
+
+	subs := []string{}
+	for k := range self.Subs {
+		subs = append(subs, k)
+	}
+	sort.Strings(subs)
+	for _, k := range subs {
+		findings = append(findings, self.Subs[k].validate()...)
+	}
+	return findings
+}
+
+func (self *Parser) destOption(dest string) *Option {
+	for _, v := range self.Opts {
+		if v.dest == dest {
+			return v
+		}
+	}
+	return nil
+}
+
+// 检查默认值与Option的类型是否匹配
+func (self *Option) checkDefault() (err error) {
+	if self.defValue == nil {
+		return nil
+	}
+	if self.setBool {
+		return fmt.Errorf("default value %v is ignored by a bool option", self.defValue)
+	}
+
+	v := &optionValue{option: self}
+	switch self.target.(type) {
+	case *int:
+		_, err = v.getInt()
+	case *time.Duration:
+		_, err = v.getDuration()
+	}
+	if err == nil && self.decoder != nil {
+		_, err = v.decode()
+	}
+	if err != nil {
+		return fmt.Errorf("bad default value %v", self.defValue)
+	}
+	return nil
+}
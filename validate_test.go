@@ -0,0 +1,88 @@
+package goargs
+
+import (
+	"strings"
+	"testing"
+)
+
+func noopHandler(c *Context) {
+}
+
+func TestValidate(t *testing.T) {
+	var count int
+
+	root := ArgumentParser("app", "Sample tool for goargs")
+	root.AddOption("mode", "testing mode").Short('m').Long("mode").Default("test")
+	root.AddOption("force", "").Short('f').Bool(true).Default("true")
+	root.AddOption("dryRun", "dry run").Bool(true)
+	root.AddOption("count", "count").Long("count").Default("x").IntVar(&count)
+	root.AddOption("verbose", "verbose output").Long("verbose").Local()
+	root.SetDefaults(noopHandler)
+
+	upload := root.AddParser("upload", "Upload file to cloud")
+	upload.AddOption("file", "file name").Required().Default("test")
+	upload.AddOption("mode", "upload mode").Long("upload-mode")
+	upload.AddOption("verbose", "verbose output").Long("upload-verbose")
+	upload.SetDefaults(noopHandler)
+
+	download := root.AddParser("download", "")
+	download.AddOption("out", "file name").Short('f')
+
+	lines := []string{}
+	for _, f := range root.Validate() {
+		lines = append(lines, f.String())
+	}
+	expect := []string{
+		"error: app download: Conflicting option -f: 'out' in download shadows 'force' in root",
+		"error: app --count: bad default value x",
+		"warning: app --dryRun: long option '--dryRun' is not kebab-case",
+		"warning: app -f: empty help",
+		"error: app -f: default value true is ignored by a bool option",
+		"warning: app download: empty help",
+		"error: app download: missing handler",
+		"warning: app upload --file: required option has a default value",
+		"warning: app upload --upload-mode: dest 'mode' collides with the inherited option '-m/--mode' in app",
+	}
+	assertEqual(t, strings.Join(expect, "\n"), strings.Join(lines, "\n"))
+}
+
+func TestValidateClean(t *testing.T) {
+	root := ArgumentParser("app", "Sample tool for goargs")
+	root.AddOption("mode", "testing mode").Short('m').Long("mode").Default("test")
+	upload := root.AddParser("upload", "Upload file to cloud")
+	upload.AddOption("file", "file name").Required()
+	upload.SetDefaults(noopHandler)
+
+	if findings := root.Validate(); len(findings) != 0 {
+		t.Error(findings)
+	}
+}
+
+func TestValidateImplicitLong(t *testing.T) {
+	root := ArgumentParser("app", "Sample tool for goargs")
+	root.AddOption("output", "output file")
+	root.SetDefaults(noopHandler)
+	root.AddLazyParser("upload", "Upload file to cloud", func(p *Parser) {
+		p.AddOption("path", "upload path").Long("output")
+		p.SetDefaults(noopHandler)
+	})
+
+	expect := "error: app upload: Conflicting option --output: 'path' in upload shadows 'output' in root"
+	findings := root.Validate()
+	if len(findings) != 1 {
+		t.Fatal(findings)
+	}
+	assertEqual(t, expect, findings[0].String())
+
+	// 子命令只返回自身及其子命令的问题
+	findings = root.Subs["upload"].Validate()
+	if len(findings) != 1 {
+		t.Fatal(findings)
+	}
+	assertEqual(t, expect, findings[0].String())
+
+	// 与ParseArgs的结果一致
+	if result := root.ParseArgs([]string{"upload"}); result.err == nil {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,233 @@
+package goargs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 一次解析中dest的参数值，与Option的定义分离，同一个Parser可以并发解析
+type optionValue struct {
+	option *Option     // 保存该dest的Option
+	value  interface{} // 参数的值
+	stored bool        // 标记是否已经设置过参数值
+	source Source      // 参数值的来源
+}
+
+// 参数值，未设置时为默认值
+func (self *optionValue) rawValue() interface{} {
+	if self.value == nil {
+		return self.option.defValue
+	}
+	return self.value
+}
+
+func (self *optionValue) getString() string {
+	switch v := self.rawValue().(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case []interface{}:
+		return strings.Join(toStringList(v), ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (self *optionValue) getInt() (int, error) {
+	switch v := self.rawValue().(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	default:
+		i, err := strconv.Atoi(self.getString())
+		if err != nil {
+			return 0, fmt.Errorf("Invalid int value '%s' for option: '%s'", self.getString(), self.option.getOptString())
+		}
+		return i, nil
+	}
+}
+
+func (self *optionValue) getDuration() (time.Duration, error) {
+	switch v := self.rawValue().(type) {
+	case nil:
+		return 0, nil
+	case time.Duration:
+		return v, nil
+	default:
+		d, err := time.ParseDuration(self.getString())
+		if err != nil {
+			return 0, fmt.Errorf("Invalid duration value '%s' for option: '%s'", self.getString(), self.option.getOptString())
+		}
+		return d, nil
+	}
+}
+
+func (self *optionValue) getStringSlice() []string {
+	switch v := self.rawValue().(type) {
+	case nil:
+		return nil
+	case []string:
+		return v
+	case []interface{}:
+		return toStringList(v)
+	default:
+		if s := self.getString(); s != "" {
+			return strings.Split(s, ",")
+		}
+		return nil
+	}
+}
+
+// Bool类型的Option未出现时，值为Bool设置值的相反值
+func (self *optionValue) getBool() bool {
+	if b, ok := self.value.(bool); ok && self.stored {
+		return b
+	}
+	if self.option.setBool {
+		return !self.option.boolV
+	}
+	b, _ := self.rawValue().(bool)
+	return b
+}
+
+func (self *optionValue) valid() error {
+	if self.stored && self.value == nil && self.option.defValue == nil {
+		return errors.New(fmt.Sprintf("Missing required option: '%s'", self.option.getOptString()))
+	}
+
+	if !self.stored && self.option.defValue == nil {
+		return errors.New(fmt.Sprintf("Missing required option: '%s'", self.option.getOptString()))
+	}
+	return nil
+}
+
+// 检查Value是否合法，并赋值
+func (self *optionValue) parse(v interface{}) (err error) {
+	// 位置参数的值不按逗号拆分
+	split := func(s string) []string {
+		if self.option.multi && !self.option.isArg {
+			return strings.Split(s, ",")
+		}
+		return []string{s}
+	}
+	if s, ok := v.(string); ok {
+		for _, item := range split(s) {
+			if err = self.option.checkChoice(item); err != nil {
+				return
+			}
+		}
+	}
+	// 可重复的参数，追加到已有的值
+	if s, ok := v.(string); ok && self.option.multi {
+		if current, ok := self.value.([]interface{}); ok {
+			// 与AppendConst共享dest
+			v = append(current, toList(split(s))...)
+		} else {
+			current, _ := self.value.([]string)
+			v = append(current, split(s)...)
+		}
+	}
+	self.stored = true
+	self.value = v
+	self.source = Source{Kind: CommandLine}
+
+	err = self.valid()
+	return
+}
+
+// 设置来自环境变量或配置文件的值
+func (self *optionValue) set(s string, src Source) (err error) {
+	if self.option.setBool {
+		b, e := strconv.ParseBool(s)
+		if e != nil {
+			return fmt.Errorf("Invalid bool value '%s' for option: '%s'", s, self.option.getOptString())
+		}
+		err = self.parse(b)
+	} else {
+		err = self.parse(s)
+	}
+	self.source = src
+	return
+}
+
+// 获取参数值的来源，Bool类型的Option总是有默认值
+func (self *optionValue) getSource() Source {
+	if self.stored {
+		return self.source
+	}
+	if self.option.defValue != nil || self.option.setBool {
+		return Source{Kind: Default}
+	}
+	return Source{Kind: Unset}
+}
+
+// 用于展示的参数值
+func (self *optionValue) getValueString() string {
+	if self.option.setBool {
+		return strconv.FormatBool(self.getBool())
+	}
+	if self.option.secret {
+		return redactValue(self.getString())
+	}
+	return self.getString()
+}
+
+// 获取带类型的参数值
+func (self *optionValue) decode() (interface{}, error) {
+	if self.option.setBool {
+		return self.getBool(), nil
+	}
+	if self.option.multi {
+		return self.getStringSlice(), nil
+	}
+	v := self.rawValue()
+	s, ok := v.(string)
+	if !ok || self.option.decoder == nil {
+		return v, nil
+	}
+	d, err := self.option.decoder(s)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid value '%s' for option: '%s'", s, self.option.getOptString())
+	}
+	return d, nil
+}
+
+// 将参数值写入绑定的变量
+func (self *optionValue) bind() (err error) {
+	if self.option.decoder != nil {
+		if _, err = self.decode(); err != nil {
+			return
+		}
+	}
+	switch p := self.option.target.(type) {
+	case *string:
+		*p = self.getString()
+	case *int:
+		*p, err = self.getInt()
+	case *bool:
+		*p = self.getBool()
+	case *time.Duration:
+		*p, err = self.getDuration()
+	case *[]string:
+		*p = self.getStringSlice()
+	}
+	return
+}
+
+// 后处理，检查所有必选参数是否已经设置
+func (self *optionValue) post() (err error) {
+	if self.option.requiredV && self.option.defValue == nil && self.value == nil {
+		if self.option.isArg {
+			return fmt.Errorf("Missing required argument: '%s'", self.option.getOptString())
+		}
+		err = errors.New(fmt.Sprintf("Missing required option: '%s'", self.option.getOptString()))
+	}
+	return
+}
@@ -0,0 +1,144 @@
+package goargs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"text/template"
+)
+
+// 默认的版本信息模板
+const VersionTemplate = `{{ .Name }} version {{ .Version }}{{ with .Revision }} ({{ . }}{{ if $.Dirty }}, dirty{{ end }}){{ end }}{{ with .Time }} {{ . }}{{ end }}`
+
+// 版本信息，--version 时通过模板输出，version --json 时输出JSON
+type VersionInfo struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`   // VCS的提交
+	Dirty     bool   `json:"dirty,omitempty"`      // 构建时工作区是否有未提交的修改
+	Time      string `json:"time,omitempty"`       // VCS的提交时间
+	GoVersion string `json:"go_version,omitempty"` // 构建使用的Go版本
+}
+
+// 设置版本号，支持 --version、-V 以及 version 子命令，比如：
+//
+//	./app --version
+//	./app version --json
+//
+// 用户定义了同名的Option或子命令时，内置的不生效
+func (self *Parser) Version(v string) *Parser {
+	if self.version == nil {
+		self.version = &VersionInfo{}
+	}
+	self.version.Version = v
+	return self
+}
+
+// 从debug.ReadBuildInfo读取模块版本、VCS提交、是否有未提交的修改以及提交时间
+// 已经通过Version设置的版本号优先，都没有时为 unknown
+func (self *Parser) VersionFromBuildInfo() *Parser {
+	v := VersionInfo{}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		v = buildVersion(info)
+	}
+	if s := self.versionString(); s != "" {
+		v.Version = s
+	}
+	if v.Version == "" {
+		v.Version = "unknown"
+	}
+	self.version = &v
+	return self
+}
+
+func (self *Parser) versionString() string {
+	if self.version == nil {
+		return ""
+	}
+	return self.version.Version
+}
+
+func buildVersion(info *debug.BuildInfo) VersionInfo {
+	v := VersionInfo{Version: info.Main.Version, GoVersion: info.GoVersion}
+	// go build 本地模块时没有版本号，提交信息显示在Revision中
+	if v.Version == "(devel)" {
+		v.Version = ""
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			v.Revision = s.Value
+		case "vcs.modified":
+			v.Dirty = s.Value == "true"
+		case "vcs.time":
+			v.Time = s.Value
+		}
+	}
+	return v
+}
+
+// 设置当前Parser以及子命令的版本信息模板，模板中可以使用VersionInfo的字段
+func (self *Parser) SetVersionTemplate(tmpl string) {
+	self.versionTmpl = tmpl
+}
+
+// 向上查找最近设置的版本信息，Name为所属命令的路径
+func (self *Parser) versionInfo() *VersionInfo {
+	for p := self; p != nil; p = p.Super {
+		if p.version != nil {
+			v := *p.version
+			v.Name = p.CommandPath()
+			return &v
+		}
+	}
+	return nil
+}
+
+func (self *Parser) versionTemplate() string {
+	for p := self; p != nil; p = p.Super {
+		if p.versionTmpl != "" {
+			return p.versionTmpl
+		}
+	}
+	return VersionTemplate
+}
+
+func (self *Parser) versionText(asJSON bool) (string, error) {
+	v := self.versionInfo()
+	if asJSON {
+		b, err := json.MarshalIndent(v, "", "  ")
+		return string(b), err
+	}
+	t, err := template.New("version").Parse(self.versionTemplate())
+	if err != nil {
+		return "", err
+	}
+	b := new(bytes.Buffer)
+	if err = t.Execute(b, v); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// 输出版本信息，返回ERR_Usage
+func (self *Parser) printVersion(asJSON bool) error {
+	s, err := self.versionText(asJSON)
+	if err != nil {
+		return err
+	}
+	fmt.Println(s)
+	return ERR_Usage
+}
+
+// 内置的version子命令，支持 --json
+func (self *Parser) printVersionCommand(params []string) error {
+	asJSON := false
+	for _, s := range params {
+		if s != "--json" {
+			return fmt.Errorf("Unrecognized arguments: %s", s)
+		}
+		asJSON = true
+	}
+	return self.printVersion(asJSON)
+}
@@ -0,0 +1,91 @@
+package goargs
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	parser := ArgumentParser("app", "help")
+	upload := parser.AddParser("upload", "upload files")
+
+	// 未设置版本号时不支持
+	if result := parser.ParseArgs([]string{"--version"}); result.err == nil {
+		t.Fatal()
+	}
+	if result := parser.ParseArgs([]string{"version"}); result.err != ERR_NotFound {
+		t.Fatal(result.err)
+	}
+
+	parser.Version("1.2.3")
+	for _, args := range [][]string{{"--version"}, {"-V"}, {"upload", "-V"}, {"version"}, {"version", "--json"}} {
+		if result := parser.ParseArgs(args); result.err != ERR_Usage {
+			t.Error(args, result.err)
+		}
+	}
+	if result := parser.ParseArgs([]string{"version", "--yaml"}); result.err == nil {
+		t.Error()
+	}
+
+	s, _ := upload.versionText(false)
+	assertEqual(t, "app version 1.2.3", s)
+	s, _ = parser.versionText(true)
+	assertEqual(t, "{\n  \"name\": \"app\",\n  \"version\": \"1.2.3\"\n}", s)
+
+	// 子命令可以单独设置模板
+	upload.SetVersionTemplate("{{ .Version }}")
+	s, _ = upload.versionText(false)
+	assertEqual(t, "1.2.3", s)
+	s, _ = parser.versionText(false)
+	assertEqual(t, "app version 1.2.3", s)
+
+	// 允许缩写时，内置的 --version 不会匹配以其开头的Option
+	parser.SetAllowAbbrev(true)
+	parser.AddOption("versionFile", "version file").Long("version-file")
+	if result := parser.ParseArgs([]string{"--version"}); result.err != ERR_Usage {
+		t.Error(result.err)
+	}
+	result := parser.ParseArgs([]string{"--version-f", "v.txt"})
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	file, _ := result.cx.GetString("versionFile")
+	assertEqual(t, "v.txt", file)
+
+	// 用户定义的Option优先
+	parser.AddOption("verbose", "verbose output").Short('V').Bool(true)
+	if result := parser.ParseArgs([]string{"-V"}); result.err != nil {
+		t.Error(result.err)
+	}
+}
+
+func TestBuildVersion(t *testing.T) {
+	info := &debug.BuildInfo{
+		GoVersion: "go1.21.0",
+		Main:      debug.Module{Path: "example.com/app", Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "4f3c2a1"},
+			{Key: "vcs.modified", Value: "true"},
+			{Key: "vcs.time", Value: "2024-01-02T03:04:05Z"},
+		},
+	}
+	parser := ArgumentParser("app", "help")
+	v := buildVersion(info)
+	v.Name = "app"
+	parser.version = &v
+	s, _ := parser.versionText(false)
+	assertEqual(t, "app version v1.2.3 (4f3c2a1, dirty) 2024-01-02T03:04:05Z", s)
+
+	// 本地构建的模块没有版本号，VersionFromBuildInfo显示为 unknown
+	info.Main.Version = "(devel)"
+	v = buildVersion(info)
+	assertEqual(t, "", v.Version)
+	assertEqual(t, "4f3c2a1", v.Revision)
+
+	// 测试二进制中没有VCS信息，显式设置的版本号优先
+	parser = ArgumentParser("app", "help").Version("1.0.0").VersionFromBuildInfo()
+	assertEqual(t, "1.0.0", parser.versionInfo().Version)
+	if parser.versionInfo().GoVersion == "" {
+		t.Error("Expect the go version from build info")
+	}
+}
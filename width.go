@@ -0,0 +1,156 @@
+package goargs
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 终端宽度未知时的默认值
+const defaultHelpWidth = 80
+
+// 描述的最小宽度，Option名称过长时不再继续压缩描述
+const minHelpWidth = 20
+
+// 东亚宽字符以及emoji，显示时占两列
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x2E80, 0x303E},   // CJK部首、标点
+	{0x3041, 0x33FF},   // 平假名、片假名、CJK兼容
+	{0x3400, 0x4DBF},   // CJK扩展A
+	{0x4E00, 0x9FFF},   // CJK统一表意文字
+	{0xA000, 0xA4CF},   // 彝文
+	{0xAC00, 0xD7A3},   // 韩文音节
+	{0xF900, 0xFAFF},   // CJK兼容表意文字
+	{0xFE30, 0xFE4F},   // CJK兼容形式
+	{0xFF00, 0xFF60},   // 全角字符
+	{0xFFE0, 0xFFE6},   // 全角符号
+	{0x1F300, 0x1F64F}, // emoji
+	{0x1F680, 0x1F6FF}, // 交通和地图符号
+	{0x1F900, 0x1F9FF}, // 补充符号和象形文字
+	{0x20000, 0x3FFFD}, // CJK扩展B及之后
+}
+
+// 字符显示的列数
+func runeWidth(r rune) int {
+	if r == 0 || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, v := range wideRanges {
+		if r >= v[0] && r <= v[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// 字符串显示的列数
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// 按显示宽度在右侧补齐空格
+func padRight(s string, width int) string {
+	if n := displayWidth(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// 帮助信息的宽度：指定的宽度 > 环境变量COLUMNS > 默认值
+func (self helpStyle) lineWidth() int {
+	if self.width > 0 {
+		return self.width
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultHelpWidth
+}
+
+type wrapToken struct {
+	text  string
+	space bool // 与前一个token之间是否有空白
+}
+
+// 拆分为可以换行的单元：连续的非空白字符为一个单元，每个宽字符单独为一个单元
+func wrapTokens(s string) []wrapToken {
+	tokens := []wrapToken{}
+	word := []rune{}
+	space := false
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, wrapToken{text: string(word), space: space})
+			word, space = word[:0], false
+		}
+	}
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+			space = true
+		case runeWidth(r) == 2:
+			flush()
+			tokens = append(tokens, wrapToken{text: string(r), space: space})
+			space = false
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// 按显示宽度换行，保留原有的换行
+func wrapText(s string, width int) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(s, "\n") {
+		line, n := "", 0
+		for _, tok := range wrapTokens(paragraph) {
+			w := displayWidth(tok.text)
+			sep := 0
+			if tok.space && line != "" {
+				sep = 1
+			}
+			if line != "" && n+sep+w > width {
+				lines = append(lines, line)
+				line, n = tok.text, w
+				continue
+			}
+			if sep == 1 {
+				line += " "
+			}
+			line += tok.text
+			n += sep + w
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// 两列对齐的文本，第二列按宽度换行，续行使用悬挂缩进；每一行可能包含多个换行
+func alignRows(prefixes []string, helps []string, width int) []string {
+	maxlen := 0
+	for _, v := range prefixes {
+		if n := displayWidth(v); n > maxlen {
+			maxlen = n
+		}
+	}
+	helpWidth := width - maxlen - 1
+	if helpWidth < minHelpWidth {
+		helpWidth = minHelpWidth
+	}
+
+	rows := []string{}
+	indent := "\n" + strings.Repeat(" ", maxlen+1)
+	for i, prefix := range prefixes {
+		lines := wrapText(helps[i], helpWidth)
+		rows = append(rows, padRight(prefix, maxlen)+" "+strings.Join(lines, indent))
+	}
+	return rows
+}
@@ -0,0 +1,76 @@
+package goargs
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestDisplayWidth(t *testing.T) {
+	assertEqualInt(t, 3, displayWidth("abc"))
+	assertEqualInt(t, 8, displayWidth("测试模式"))
+	assertEqualInt(t, 8, displayWidth("ｍｏｄｅ"))
+	assertEqualInt(t, 4, displayWidth("ok😀"))
+	// 组合字符不占宽度
+	assertEqualInt(t, 1, displayWidth("é"))
+	assertEqual(t, "中文  |", padRight("中文", 6)+"|")
+}
+
+func TestWrapText(t *testing.T) {
+	assertEqual(t, "the quick|brown fox|jumps", strings.Join(wrapText("the quick brown fox jumps", 10), "|"))
+	// 宽字符之间可以换行，与英文单词之间不加空格
+	assertEqual(t, "上传文件到|云存储ok", strings.Join(wrapText("上传文件到云存储ok", 10), "|"))
+	assertEqual(t, "上传 file|到云存储", strings.Join(wrapText("上传 file 到云存储", 10), "|"))
+	// 保留原有的换行，过长的单词不截断
+	assertEqual(t, "first|line|averyveryverylongword", strings.Join(wrapText("first\nline averyveryverylongword", 8), "|"))
+}
+
+func TestHelpGolden(t *testing.T) {
+	for _, width := range []int{40, 80, 120} {
+		parser := ArgumentParser("app", "Sample tool for goargs")
+		parser.AddOption("mode", "测试模式，可以是 fast、slow 或者 debug，默认使用 fast 模式").Short('m').Long("mode")
+		parser.AddOption("config", "config file, which is loaded before the environment variables and the command line").Short('c').Long("config")
+		parser.AddOption("force", "强制执行 😀").Short('f').Bool(true)
+		parser.AddOption("output", "output format").Long("output")
+		parser.AddOption("region", "地域").Long("region")
+		parser.AddParser("upload", "上传文件到云存储，支持断点续传以及并发上传多个分片")
+		parser.AddParser("download", "download files from the cloud storage to the local disk")
+		parser.AddParser("同步", "双向同步")
+		parser.SetHelpFormatter(&TemplateFormatter{Width: width})
+		usage, err := parser.Usage()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path := filepath.Join("testdata", fmt.Sprintf("help_%d.golden", width))
+		if *update {
+			if err := os.WriteFile(path, []byte(usage), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		golden, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, string(golden), usage)
+
+		for _, line := range strings.Split(usage, "\n") {
+			if displayWidth(line) > width {
+				t.Errorf("line is wider than %d: %q", width, line)
+			}
+		}
+	}
+}
+
+func TestHelpWidthFromEnv(t *testing.T) {
+	t.Setenv("COLUMNS", "40")
+	assertEqualInt(t, 40, helpStyle{}.lineWidth())
+	assertEqualInt(t, 120, helpStyle{width: 120}.lineWidth())
+	t.Setenv("COLUMNS", "x")
+	assertEqualInt(t, defaultHelpWidth, helpStyle{}.lineWidth())
+}